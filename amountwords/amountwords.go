@@ -0,0 +1,231 @@
+// Package amountwords spells out rupee amounts the way they are written on
+// bilties and invoices, in English or Hindi, using the Indian numbering system.
+// Amounts are handled as whole paise so no float rounding reaches the words.
+package amountwords
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Language of the words
+type Language string
+
+const (
+	English Language = "en"
+	Hindi   Language = "hi"
+)
+
+// Style decides whether paise are spelled out
+type Style int
+
+const (
+	// WithPaise gives "Rupees ... and ... Paise Only"
+	WithPaise Style = iota
+	// RupeesOnly gives "Rupees ... Only", rounding paise to the nearest rupee
+	RupeesOnly
+)
+
+// Converter spells amounts with a fixed language and style. The zero value
+// writes English with paise and repeats crore for large amounts.
+type Converter struct {
+	Language Language
+	Style    Style
+	// ArabKharab names 100 crore an arab and 100 arab a kharab instead of
+	// repeating crore ("One Hundred Crore")
+	ArabKharab bool
+}
+
+// Default is the converter bilties are printed with
+var Default = Converter{}
+
+// scale is one unit of the Indian numbering system
+type scale struct {
+	value   uint64
+	english string
+	hindi   string
+}
+
+// Scales from the largest down; arab and kharab are skipped unless asked for
+var (
+	kharab   = scale{100000000000, "Kharab", "खरब"}
+	arab     = scale{1000000000, "Arab", "अरब"}
+	crore    = scale{10000000, "Crore", "करोड़"}
+	lakh     = scale{100000, "Lakh", "लाख"}
+	thousand = scale{1000, "Thousand", "हज़ार"}
+	hundred  = scale{100, "Hundred", "सौ"}
+)
+
+var englishOnes = []string{
+	"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine",
+	"Ten", "Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen",
+	"Sixteen", "Seventeen", "Eighteen", "Nineteen",
+}
+
+var englishTens = []string{
+	"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety",
+}
+
+// hindiNumbers are the words for 0-99; Hindi has no regular tens pattern
+var hindiNumbers = []string{
+	"शून्य", "एक", "दो", "तीन", "चार", "पाँच", "छह", "सात", "आठ", "नौ",
+	"दस", "ग्यारह", "बारह", "तेरह", "चौदह", "पंद्रह", "सोलह", "सत्रह", "अठारह", "उन्नीस",
+	"बीस", "इक्कीस", "बाईस", "तेईस", "चौबीस", "पच्चीस", "छब्बीस", "सत्ताईस", "अट्ठाईस", "उनतीस",
+	"तीस", "इकतीस", "बत्तीस", "तैंतीस", "चौंतीस", "पैंतीस", "छत्तीस", "सैंतीस", "अड़तीस", "उनतालीस",
+	"चालीस", "इकतालीस", "बयालीस", "तैंतालीस", "चौवालीस", "पैंतालीस", "छियालीस", "सैंतालीस", "अड़तालीस", "उनचास",
+	"पचास", "इक्यावन", "बावन", "तिरपन", "चौवन", "पचपन", "छप्पन", "सत्तावन", "अट्ठावन", "उनसठ",
+	"साठ", "इकसठ", "बासठ", "तिरसठ", "चौंसठ", "पैंसठ", "छियासठ", "सड़सठ", "अड़सठ", "उनहत्तर",
+	"सत्तर", "इकहत्तर", "बहत्तर", "तिहत्तर", "चौहत्तर", "पचहत्तर", "छिहत्तर", "सतहत्तर", "अठहत्तर", "उन्यासी",
+	"अस्सी", "इक्यासी", "बयासी", "तिरासी", "चौरासी", "पचासी", "छियासी", "सत्तासी", "अट्ठासी", "नवासी",
+	"नब्बे", "इक्यानबे", "बानबे", "तिरानबे", "चौरानबे", "पचानबे", "छियानबे", "सत्तानबे", "अट्ठानबे", "निन्यानबे",
+}
+
+// phrases are the fixed words around the numbers
+type phrases struct {
+	zero, minus, rupees, paise, and, only string
+}
+
+var englishPhrases = phrases{"Zero", "Minus", "Rupees", "Paise", "and", "Only"}
+var hindiPhrases = phrases{"शून्य", "ऋण", "रुपये", "पैसे", "और", "मात्र"}
+
+func (c Converter) phrases() phrases {
+	if c.Language == Hindi {
+		return hindiPhrases
+	}
+	return englishPhrases
+}
+
+func (c Converter) scales() []scale {
+	if c.ArabKharab {
+		return []scale{kharab, arab, crore, lakh, thousand, hundred}
+	}
+	return []scale{crore, lakh, thousand, hundred}
+}
+
+// Number spells a whole number, e.g. 150000 is "One Lakh Fifty Thousand".
+// 0 is "Zero" and negative numbers start with "Minus".
+func (c Converter) Number(n int64) string {
+	p := c.phrases()
+	switch {
+	case n == 0:
+		return p.zero
+	case n < 0:
+		// Negating math.MinInt64 overflows, so work on the magnitude as uint64
+		return p.minus + " " + c.number(uint64(-(n+1))+1)
+	}
+	return c.number(uint64(n))
+}
+
+// number spells n > 0
+func (c Converter) number(n uint64) string {
+	if n < 100 {
+		return c.belowHundred(n)
+	}
+	for _, s := range c.scales() {
+		if n < s.value {
+			continue
+		}
+		unit := s.english
+		if c.Language == Hindi {
+			unit = s.hindi
+		}
+		// The largest unit repeats when the count doesn't fit below it,
+		// e.g. "One Hundred Crore" or "One Lakh Kharab"
+		words := c.number(n/s.value) + " " + unit
+		if rest := n % s.value; rest > 0 {
+			words += " " + c.number(rest)
+		}
+		return words
+	}
+	return ""
+}
+
+func (c Converter) belowHundred(n uint64) string {
+	if c.Language == Hindi {
+		return hindiNumbers[n]
+	}
+	if n < 20 {
+		return englishOnes[n]
+	}
+	return strings.TrimSpace(englishTens[n/10] + " " + englishOnes[n%10])
+}
+
+// Paise spells an amount given in paise, e.g. 245050 is
+// "Rupees Two Thousand Four Hundred Fifty and Fifty Paise Only"
+func (c Converter) Paise(paise int64) string {
+	p := c.phrases()
+
+	negative := paise < 0
+	magnitude := uint64(paise)
+	if negative {
+		magnitude = uint64(-(paise + 1)) + 1
+	}
+	rupees, rest := magnitude/100, magnitude%100
+	if c.Style == RupeesOnly {
+		if rest >= 50 {
+			rupees++
+		}
+		rest = 0
+	}
+
+	var words string
+	switch {
+	case rupees == 0 && rest == 0:
+		return c.phrase(p.rupees, p.zero) + " " + p.only
+	case rupees == 0:
+		words = c.number(rest) + " " + p.paise
+	default:
+		words = c.phrase(p.rupees, c.number(rupees))
+		if rest > 0 {
+			words += " " + p.and + " " + c.number(rest) + " " + p.paise
+		}
+	}
+	if negative {
+		words = p.minus + " " + words
+	}
+	return words + " " + p.only
+}
+
+// phrase puts the currency before the number in English ("Rupees Ten") and
+// after it in Hindi ("दस रुपये")
+func (c Converter) phrase(currency, number string) string {
+	if c.Language == Hindi {
+		return number + " " + currency
+	}
+	return currency + " " + number
+}
+
+// Rupees spells a rupee amount; see ToPaise for how it is rounded
+func (c Converter) Rupees(amount float64) string {
+	return c.Paise(ToPaise(amount))
+}
+
+// ToPaise converts a rupee amount to whole paise, rounding half away from
+// zero on the decimal digits the amount is written with, so 0.995 becomes
+// 100 paise rather than the 99 its binary value would give. Amounts beyond
+// the int64 range of paise are clamped to it.
+func ToPaise(amount float64) int64 {
+	if math.IsNaN(amount) {
+		return 0
+	}
+	s := strconv.FormatFloat(math.Abs(amount), 'f', -1, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	frac += "000"
+
+	rupees, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rupees >= math.MaxInt64/100 {
+		if amount < 0 {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	paise := rupees*100 + int64(frac[0]-'0')*10 + int64(frac[1]-'0')
+	if frac[2] >= '5' {
+		paise++
+	}
+	if amount < 0 {
+		return -paise
+	}
+	return paise
+}
@@ -0,0 +1,108 @@
+package amountwords
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		conv Converter
+		n    int64
+		want string
+	}{
+		{"zero", Default, 0, "Zero"},
+		{"teen", Default, 15, "Fifteen"},
+		{"tens", Default, 90, "Ninety"},
+		{"tens and ones", Default, 42, "Forty Two"},
+		{"hundred", Default, 100, "One Hundred"},
+		{"hundreds", Default, 999, "Nine Hundred Ninety Nine"},
+		{"thousand", Default, 1000, "One Thousand"},
+		{"lakh", Default, 150000, "One Lakh Fifty Thousand"},
+		{"crore", Default, 10000000, "One Crore"},
+		{"full crore", Default, 12345678, "One Crore Twenty Three Lakh Forty Five Thousand Six Hundred Seventy Eight"},
+		{"hundred crore", Default, 1000000000, "One Hundred Crore"},
+		{"crore of crores", Default, 100000000000000, "One Crore Crore"},
+		{"arab", Converter{ArabKharab: true}, 1000000000, "One Arab"},
+		{"arab and crore", Converter{ArabKharab: true}, 2500000000, "Two Arab Fifty Crore"},
+		{"kharab", Converter{ArabKharab: true}, 300000000000, "Three Kharab"},
+		{"lakh kharab", Converter{ArabKharab: true}, 10000000000000000, "One Lakh Kharab"},
+		{"negative", Default, -250, "Minus Two Hundred Fifty"},
+		{"min int64", Default, math.MinInt64, "Minus Ninety Two Thousand Two Hundred Thirty Three Crore Seventy Two Lakh Three Thousand Six Hundred Eighty Five Crore Forty Seven Lakh Seventy Five Thousand Eight Hundred Eight"},
+		{"hindi zero", Converter{Language: Hindi}, 0, "शून्य"},
+		{"hindi irregular", Converter{Language: Hindi}, 79, "उन्यासी"},
+		{"hindi lakh", Converter{Language: Hindi}, 250000, "दो लाख पचास हज़ार"},
+		{"hindi crore", Converter{Language: Hindi}, 12345678, "एक करोड़ तेईस लाख पैंतालीस हज़ार छह सौ अठहत्तर"},
+		{"hindi arab", Converter{Language: Hindi, ArabKharab: true}, 1100000000, "एक अरब दस करोड़"},
+		{"hindi negative", Converter{Language: Hindi}, -5, "ऋण पाँच"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.conv.Number(tt.n); got != tt.want {
+				t.Errorf("Number(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaise(t *testing.T) {
+	tests := []struct {
+		name  string
+		conv  Converter
+		paise int64
+		want  string
+	}{
+		{"zero", Default, 0, "Rupees Zero Only"},
+		{"rupees", Default, 245000, "Rupees Two Thousand Four Hundred Fifty Only"},
+		{"rupees and paise", Default, 245050, "Rupees Two Thousand Four Hundred Fifty and Fifty Paise Only"},
+		{"paise only", Default, 5, "Five Paise Only"},
+		{"negative", Default, -1025, "Minus Rupees Ten and Twenty Five Paise Only"},
+		{"hundred crore", Default, 100000000000, "Rupees One Hundred Crore Only"},
+		{"arab", Converter{ArabKharab: true}, 100000000000, "Rupees One Arab Only"},
+		{"rupees only rounds down", Converter{Style: RupeesOnly}, 1049, "Rupees Ten Only"},
+		{"rupees only rounds up", Converter{Style: RupeesOnly}, 1050, "Rupees Eleven Only"},
+		{"rupees only below a rupee", Converter{Style: RupeesOnly}, 40, "Rupees Zero Only"},
+		{"hindi", Converter{Language: Hindi}, 245050, "दो हज़ार चार सौ पचास रुपये और पचास पैसे मात्र"},
+		{"hindi zero", Converter{Language: Hindi}, 0, "शून्य रुपये मात्र"},
+		{"hindi paise only", Converter{Language: Hindi}, 50, "पचास पैसे मात्र"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.conv.Paise(tt.paise); got != tt.want {
+				t.Errorf("Paise(%d) = %q, want %q", tt.paise, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToPaise(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{0, 0},
+		{1, 100},
+		{2450.5, 245050},
+		{0.995, 100},
+		{1.005, 101},
+		{0.1 + 0.2, 30},
+		{19.999, 2000},
+		{-0.995, -100},
+		{-12.34, -1234},
+		{math.NaN(), 0},
+		{math.Inf(1), math.MaxInt64},
+		{-1e30, math.MinInt64},
+	}
+	for _, tt := range tests {
+		if got := ToPaise(tt.amount); got != tt.want {
+			t.Errorf("ToPaise(%v) = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestRupees(t *testing.T) {
+	if got, want := Default.Rupees(0.995), "Rupees One Only"; got != want {
+		t.Errorf("Rupees(0.995) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Command reconcile-pdfs compares stored bilty PDFs with the locations saved
+// in the database. Objects nothing refers to are deleted (or only listed with
+// -dry-run), and locations pointing at missing objects are reported.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hariomtransport/backend/config"
+	"github.com/hariomtransport/backend/db/mongo"
+	"github.com/hariomtransport/backend/db/postgres"
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/storage"
+	"github.com/hariomtransport/backend/utils"
+)
+
+// legacyKeyPrefix matches PDFs stored flat before keys were grouped by date
+const legacyKeyPrefix = "bilty_"
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report orphans, don't delete them")
+	minAge := flag.Duration("min-age", time.Hour, "skip objects younger than this, they may still be saving")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+
+	var biltyRepo repository.BiltyRepository
+	var auditRepo repository.AuditRepository
+	switch cfg.DBType {
+	case "postgres":
+		pg := postgres.NewPostgresDB(cfg.PostgresURL)
+		if err := pg.Connect(); err != nil {
+			log.Fatal(err)
+		}
+		defer pg.Disconnect()
+		biltyRepo = repository.NewPostgresBiltyRepo(pg.Conn)
+		auditRepo = repository.NewPostgresAuditRepo(pg.Conn)
+
+	case "mongo":
+		mg := mongo.NewMongoDB(cfg.MongoURL)
+		if err := mg.Connect(); err != nil {
+			log.Fatal(err)
+		}
+		defer mg.Disconnect()
+		biltyRepo = repository.NewMongoBiltyRepo(mg.Client)
+		auditRepo = repository.NewMongoAuditRepo(mg.Client)
+
+	default:
+		log.Fatal("DB_TYPE not supported")
+	}
+
+	store, err := storage.FromConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	auditor := &utils.Auditor{Repo: auditRepo}
+	if err := reconcile(context.Background(), biltyRepo, store, auditor, *dryRun, *minAge); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func reconcile(ctx context.Context, repo repository.BiltyRepository, store storage.Storage, auditor *utils.Auditor, dryRun bool, minAge time.Duration) error {
+	locations, err := repo.PDFLocations()
+	if err != nil {
+		return fmt.Errorf("failed to load PDF locations: %w", err)
+	}
+	referenced := make(map[string]bool, len(locations))
+	for _, loc := range locations {
+		referenced[storage.KeyFromLocation(loc)] = true
+	}
+
+	var objects []storage.ObjectInfo
+	for _, prefix := range []string{utils.PDFKeyPrefix, legacyKeyPrefix} {
+		found, err := store.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, found...)
+	}
+
+	cutoff := time.Now().Add(-minAge)
+	stored := make(map[string]bool, len(objects))
+	var orphans, deleted, skipped int
+	for _, obj := range objects {
+		stored[obj.Key] = true
+		if referenced[obj.Key] {
+			continue
+		}
+		if obj.LastModified.After(cutoff) {
+			skipped++
+			continue
+		}
+
+		orphans++
+		if dryRun {
+			fmt.Printf("orphan  %s (%d bytes, %s)\n", obj.Key, obj.Size, obj.LastModified.Format(time.RFC3339))
+			continue
+		}
+		if err := store.Delete(ctx, obj.Key); err != nil {
+			fmt.Printf("⚠️ Failed to delete %s: %v\n", obj.Key, err)
+			continue
+		}
+		deleted++
+		fmt.Printf("deleted %s\n", obj.Key)
+		auditor.Record(ctx, &models.AuditEvent{
+			Action:    models.AuditPDFDeleted,
+			Entity:    models.AuditEntityPDF,
+			EntityID:  obj.Key,
+			ActorName: "reconcile-pdfs",
+			Payload:   map[string]interface{}{"reason": "orphan", "size": obj.Size},
+		})
+	}
+
+	missing := 0
+	for key := range referenced {
+		if !stored[key] {
+			missing++
+			fmt.Printf("missing %s\n", key)
+		}
+	}
+
+	fmt.Printf("%d objects, %d orphans (%d deleted, %d too recent to judge), %d referenced but missing\n",
+		len(objects), orphans, deleted, skipped, missing)
+	return nil
+}
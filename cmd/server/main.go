@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/hariomtransport/backend/config"
 	"github.com/hariomtransport/backend/db/mongo"
@@ -10,6 +16,10 @@ import (
 	"github.com/hariomtransport/backend/handlers"
 	"github.com/hariomtransport/backend/repository"
 	"github.com/hariomtransport/backend/routes"
+	"github.com/hariomtransport/backend/stations"
+	"github.com/hariomtransport/backend/storage"
+	"github.com/hariomtransport/backend/tally"
+	"github.com/hariomtransport/backend/utils"
 )
 
 func main() {
@@ -22,6 +32,13 @@ func main() {
 	var biltyRepo repository.BiltyRepository
 	var userRepo repository.UserRepository
 	var initialRepo repository.InitialRepository
+	var templateRepo repository.TemplateRepository
+	var jobRepo repository.JobRepository
+	var auditRepo repository.AuditRepository
+	var commodityRepo repository.CommodityRepository
+	var rateCardRepo repository.RateCardRepository
+	var reportRepo repository.ReportRepository
+	var tallyRepo repository.TallyRepository
 
 	switch cfg.DBType {
 	case "postgres":
@@ -34,6 +51,13 @@ func main() {
 		biltyRepo = repository.NewPostgresBiltyRepo(pg.Conn)
 		userRepo = repository.NewPostgresUserRepo(pg.Conn)
 		initialRepo = repository.NewPostgresInitialRepo(pg.Conn)
+		templateRepo = repository.NewPostgresTemplateRepo(pg.Conn)
+		jobRepo = repository.NewPostgresJobRepo(pg.Conn)
+		auditRepo = repository.NewPostgresAuditRepo(pg.Conn)
+		commodityRepo = repository.NewPostgresCommodityRepo(pg.Conn)
+		rateCardRepo = repository.NewPostgresRateCardRepo(pg.Conn)
+		reportRepo = repository.NewPostgresReportRepo(pg.Conn)
+		tallyRepo = repository.NewPostgresTallyRepo(pg.Conn)
 
 	case "mongo":
 		mg := mongo.NewMongoDB(cfg.MongoURL)
@@ -45,29 +69,130 @@ func main() {
 		biltyRepo = repository.NewMongoBiltyRepo(mg.Client)
 		userRepo = repository.NewMongoUserRepo(mg.Client)
 		initialRepo = repository.NewMongoInitialRepo(mg.Client)
+		templateRepo = repository.NewMongoTemplateRepo(mg.Client)
+		jobRepo = repository.NewMongoJobRepo(mg.Client)
+		auditRepo = repository.NewMongoAuditRepo(mg.Client)
+		commodityRepo = repository.NewMongoCommodityRepo(mg.Client)
+		rateCardRepo = repository.NewMongoRateCardRepo(mg.Client)
+		reportRepo = repository.NewMongoReportRepo(mg.Client)
+		tallyRepo = repository.NewMongoTallyRepo(mg.Client)
 
 	default:
 		panic("DB_TYPE not supported")
 	}
 
+	tokens := utils.NewTokenManager(cfg.AuthSecret, 24*time.Hour)
+
+	// Audit log, purged after the retention period
+	auditor := &utils.Auditor{
+		Repo:       auditRepo,
+		Retention:  time.Duration(cfg.AuditRetentionDays) * 24 * time.Hour,
+		TrustProxy: cfg.TrustProxy,
+	}
+	auditor.Start()
+	defer auditor.Stop()
+
+	// Station master for location autocomplete and normalization
+	stationDir, err := stations.Load(cfg.StationsFile)
+	if err != nil {
+		panic(err)
+	}
+
 	// Handlers
-	biltyHandler := &handlers.BiltyHandler{Repo: biltyRepo}
-	userHandler := &handlers.UserHandler{Repo: userRepo}
-	initialHandler := &handlers.InitialHandler{Repo: initialRepo}
+	biltyHandler := &handlers.BiltyHandler{Repo: biltyRepo, Stations: stationDir}
+	userHandler := &handlers.UserHandler{Repo: userRepo, Tokens: tokens, Audit: auditor}
+	initialHandler := &handlers.InitialHandler{Repo: initialRepo, Audit: auditor}
 
 	// PDF handler with combined repository
 	pdfRepo := &repository.PDFRepository{
 		BiltyRepo:   biltyRepo,
 		InitialRepo: initialRepo,
 	}
-	pdfHandler := &handlers.PDFHandler{Repo: pdfRepo}
+	templateStore := utils.NewTemplateStore(templateRepo, cfg.TemplateDir)
+	browserPool := utils.NewBrowserPool(cfg.ChromePoolSize, time.Duration(cfg.PDFRenderTimeoutSec)*time.Second)
+	defer browserPool.Close()
+	fileStore, err := storage.FromConfig(cfg)
+	if err != nil {
+		panic(err)
+	}
+	biltySigner := utils.NewBiltySigner(cfg.VerifySecret)
+	pdfGenerator := &utils.PDFGenerator{
+		Repo:          pdfRepo,
+		Templates:     templateStore,
+		Browser:       browserPool,
+		Storage:       fileStore,
+		LinkExpiry:    time.Duration(cfg.PDFLinkExpirySec) * time.Second,
+		Signer:        biltySigner,
+		PublicBaseURL: cfg.PublicBaseURL,
+		Audit:         auditor,
+	}
+
+	// Background PDF jobs
+	jobRunner := &utils.PDFJobRunner{
+		Repo:        jobRepo,
+		Generator:   pdfGenerator,
+		Workers:     cfg.PDFJobWorkers,
+		MaxAttempts: cfg.PDFJobMaxAttempts,
+		// A job renders once, then uploads; past twice the render timeout its worker is gone
+		Lease: 2 * time.Duration(cfg.PDFRenderTimeoutSec) * time.Second,
+	}
+	jobRunner.Start()
+	defer jobRunner.Stop()
+
+	pdfHandler := &handlers.PDFHandler{Repo: pdfRepo, Generator: pdfGenerator, Jobs: jobRunner}
+	templateHandler := &handlers.TemplateHandler{Repo: templateRepo, Store: templateStore, PDFRepo: pdfRepo}
+	jobHandler := &handlers.JobHandler{Repo: jobRepo, Generator: pdfGenerator}
+	verifyHandler := &handlers.VerifyHandler{Repo: pdfRepo, Signer: biltySigner, Store: templateStore}
+	auditHandler := &handlers.AuditHandler{Repo: auditRepo}
+	commodityHandler := &handlers.CommodityHandler{Repo: commodityRepo}
+	rateCardHandler := &handlers.RateCardHandler{Repo: rateCardRepo, Stations: stationDir}
+	stationHandler := &handlers.StationHandler{Stations: stationDir}
+	reportHandler := &handlers.ReportHandler{Repo: reportRepo}
+	tallyHandler := &handlers.TallyHandler{
+		Repo:    tallyRepo,
+		Initial: initialRepo,
+		Ledgers: tally.Ledgers{
+			Company: cfg.TallyCompany,
+			Freight: cfg.TallyFreightLedger,
+			Charges: cfg.TallyChargesLedger,
+			CGST:    cfg.TallyCGSTLedger,
+			SGST:    cfg.TallySGSTLedger,
+			IGST:    cfg.TallyIGSTLedger,
+			Cash:    cfg.TallyCashLedger,
+		},
+		Audit: auditor,
+	}
+
+	// The local backend serves its own files; other backends link elsewhere
+	var files http.Handler
+	if local, ok := fileStore.(*storage.Local); ok {
+		files = local
+	}
 
 	// Setup routes including PDF
-	routes.SetupRoutes(userHandler, biltyHandler, initialHandler, pdfHandler)
+	routes.SetupRoutes(userHandler, biltyHandler, initialHandler, pdfHandler, templateHandler, jobHandler, verifyHandler, auditHandler, commodityHandler, rateCardHandler, stationHandler, reportHandler, tallyHandler, files, tokens)
 
 	port := cfg.Port
+	srv := &http.Server{Addr: "0.0.0.0:" + port}
+
+	// Shut down cleanly on SIGINT/SIGTERM so deferred cleanup (browser, DB) runs
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
+
 	fmt.Printf("Server running on port %s\n", port)
-	if err := http.ListenAndServe("0.0.0.0:"+port, nil); err != nil {
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		panic(err)
 	}
+	<-shutdownDone
+	fmt.Println("Server stopped")
 }
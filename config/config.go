@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +13,41 @@ type Config struct {
 	MongoURL    string
 	DBType      string
 	Port        string
+	TemplateDir string
+	AuthSecret  string
+
+	PublicBaseURL string // public URL of this API, used in bilty QR codes
+	VerifySecret  string // signs bilty verification links
+
+	ChromePoolSize      int // max concurrent Chrome tabs for PDF rendering
+	PDFRenderTimeoutSec int // per-render timeout in seconds
+	PDFJobWorkers       int // background PDF job workers
+	PDFJobMaxAttempts   int // attempts before a PDF job is marked failed
+
+	// Object storage for generated PDFs: s3 (any S3-compatible endpoint), local or memory
+	StorageBackend         string
+	StorageDir             string // local backend directory
+	StorageEndpoint        string
+	StorageRegion          string
+	StorageBucket          string
+	StorageAccessKeyID     string
+	StorageSecretAccessKey string
+	StoragePathStyle       bool
+	PDFLinkExpirySec       int // lifetime of signed PDF download links
+
+	AuditRetentionDays int  // days audit events are kept; 0 keeps them forever
+	TrustProxy         bool // behind a reverse proxy: take client IPs from X-Forwarded-For
+
+	StationsFile string // station master JSON replacing the bundled list
+
+	// Tally export: the company vouchers are imported into and the ledgers they post to
+	TallyCompany       string
+	TallyFreightLedger string
+	TallyChargesLedger string
+	TallyCGSTLedger    string
+	TallySGSTLedger    string
+	TallyIGSTLedger    string
+	TallyCashLedger    string
 }
 
 func LoadConfig() *Config {
@@ -24,9 +60,87 @@ func LoadConfig() *Config {
 		MongoURL:    os.Getenv("MONGO_URL"),
 		DBType:      os.Getenv("DB_TYPE"),
 		Port:        os.Getenv("PORT"),
+		TemplateDir: os.Getenv("TEMPLATE_DIR"),
+		AuthSecret:  os.Getenv("AUTH_SECRET"),
+
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+		VerifySecret:  os.Getenv("VERIFY_SECRET"),
+
+		ChromePoolSize:      getEnvInt("CHROME_POOL_SIZE", 2),
+		PDFRenderTimeoutSec: getEnvInt("PDF_RENDER_TIMEOUT", 30),
+		PDFJobWorkers:       getEnvInt("PDF_JOB_WORKERS", 2),
+		PDFJobMaxAttempts:   getEnvInt("PDF_JOB_MAX_ATTEMPTS", 3),
+
+		StorageBackend: os.Getenv("STORAGE_BACKEND"),
+		StorageDir:     os.Getenv("STORAGE_DIR"),
+		StorageRegion:  os.Getenv("STORAGE_REGION"),
+		// The R2_* variables are still honoured for existing deployments
+		StorageEndpoint:        os.Getenv("STORAGE_ENDPOINT"),
+		StorageBucket:          firstEnv("STORAGE_BUCKET", "R2_BUCKET"),
+		StorageAccessKeyID:     firstEnv("STORAGE_ACCESS_KEY_ID", "R2_ACCESS_KEY_ID"),
+		StorageSecretAccessKey: firstEnv("STORAGE_SECRET_ACCESS_KEY", "R2_SECRET_ACCESS_KEY"),
+		StoragePathStyle:       os.Getenv("STORAGE_PATH_STYLE") == "true",
+		PDFLinkExpirySec:       getEnvInt("PDF_LINK_EXPIRY", 900),
+
+		AuditRetentionDays: getEnvInt("AUDIT_RETENTION_DAYS", 365),
+		TrustProxy:         os.Getenv("TRUST_PROXY") == "true",
+
+		StationsFile: os.Getenv("STATIONS_FILE"),
+
+		TallyCompany:       os.Getenv("TALLY_COMPANY"),
+		TallyFreightLedger: getEnv("TALLY_FREIGHT_LEDGER", "Freight Income"),
+		TallyChargesLedger: getEnv("TALLY_CHARGES_LEDGER", "Freight Charges"),
+		TallyCGSTLedger:    getEnv("TALLY_CGST_LEDGER", "Output CGST"),
+		TallySGSTLedger:    getEnv("TALLY_SGST_LEDGER", "Output SGST"),
+		TallyIGSTLedger:    getEnv("TALLY_IGST_LEDGER", "Output IGST"),
+		TallyCashLedger:    getEnv("TALLY_CASH_LEDGER", "Cash"),
 	}
 	if cfg.Port == "" {
 		cfg.Port = "8080"
 	}
+	if cfg.VerifySecret == "" {
+		cfg.VerifySecret = cfg.AuthSecret
+	}
+	if cfg.StorageBackend == "" {
+		cfg.StorageBackend = "s3"
+	}
+	if cfg.StorageDir == "" {
+		cfg.StorageDir = "./pdfs"
+	}
+	if accountID := os.Getenv("R2_ACCOUNT_ID"); cfg.StorageEndpoint == "" && accountID != "" {
+		cfg.StorageEndpoint = "https://" + accountID + ".r2.cloudflarestorage.com"
+	}
 	return cfg
 }
+
+// getEnv reads an env variable, falling back to def when unset
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt reads an integer env variable, falling back to def when unset or invalid
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid %s=%q, using default %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// firstEnv returns the first of the env variables that is set
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
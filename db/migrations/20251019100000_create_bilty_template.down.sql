@@ -0,0 +1 @@
+DROP TABLE IF EXISTS bilty_template;
@@ -0,0 +1,13 @@
+-- Versioned print templates; only one version per name may be active
+CREATE TABLE IF NOT EXISTS bilty_template (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT false,
+    created_by BIGINT REFERENCES app_user(id),
+    created_at TIMESTAMP DEFAULT now(),
+    activated_at TIMESTAMP,
+    UNIQUE (name, version)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bilty_template_active ON bilty_template(name) WHERE is_active;
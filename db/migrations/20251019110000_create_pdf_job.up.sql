@@ -0,0 +1,15 @@
+-- Background PDF generation jobs
+CREATE TABLE IF NOT EXISTS pdf_job (
+    id BIGSERIAL PRIMARY KEY,
+    bilty_id BIGINT NOT NULL REFERENCES bilty(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    result TEXT,
+    last_error TEXT,
+    run_after TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_pdf_job_queue ON pdf_job(status, run_after);
+CREATE INDEX IF NOT EXISTS idx_pdf_job_bilty_id ON pdf_job(bilty_id);
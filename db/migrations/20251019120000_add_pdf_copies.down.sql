@@ -0,0 +1,3 @@
+ALTER TABLE pdf_job DROP COLUMN IF EXISTS options;
+DROP TABLE IF EXISTS bilty_pdf;
+ALTER TABLE initial_setup DROP COLUMN IF EXISTS copies;
@@ -0,0 +1,21 @@
+-- Configurable copy set printed on each bilty
+ALTER TABLE initial_setup ADD COLUMN IF NOT EXISTS copies JSONB;
+
+-- Stored PDFs per print variant (copy combination)
+CREATE TABLE IF NOT EXISTS bilty_pdf (
+    bilty_id BIGINT NOT NULL REFERENCES bilty(id) ON DELETE CASCADE,
+    variant TEXT NOT NULL,
+    pdf_path TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (bilty_id, variant)
+);
+
+-- Existing PDFs were printed with the built-in copy set
+INSERT INTO bilty_pdf (bilty_id, variant, pdf_path, created_at)
+SELECT id, 'consignor+consignee+driver', pdf_path, pdf_created_at
+FROM bilty
+WHERE pdf_path IS NOT NULL AND pdf_created_at IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Print options carried by background PDF jobs
+ALTER TABLE pdf_job ADD COLUMN IF NOT EXISTS options JSONB;
@@ -0,0 +1 @@
+ALTER TABLE bilty DROP COLUMN IF EXISTS delivery_status;
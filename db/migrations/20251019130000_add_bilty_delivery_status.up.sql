@@ -0,0 +1,3 @@
+-- Delivery progress shown on the public verification page
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS delivery_status TEXT NOT NULL DEFAULT 'booked'
+    CHECK (delivery_status IN ('booked', 'in_transit', 'delivered'));
@@ -0,0 +1,5 @@
+ALTER TABLE bilty DROP COLUMN IF EXISTS reprint_count;
+
+UPDATE bilty SET status = 'draft' WHERE status = 'cancelled';
+ALTER TABLE bilty DROP CONSTRAINT IF EXISTS bilty_status_check;
+ALTER TABLE bilty ADD CONSTRAINT bilty_status_check CHECK (status IN ('draft', 'complete'));
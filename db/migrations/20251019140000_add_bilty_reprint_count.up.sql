@@ -0,0 +1,5 @@
+-- Bilties can be cancelled, and reprints of issued bilties are counted
+ALTER TABLE bilty DROP CONSTRAINT IF EXISTS bilty_status_check;
+ALTER TABLE bilty ADD CONSTRAINT bilty_status_check CHECK (status IN ('draft', 'complete', 'cancelled'));
+
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS reprint_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE bilty_pdf DROP COLUMN IF EXISTS pdf_hash;
+ALTER TABLE bilty DROP COLUMN IF EXISTS pdf_hash;
@@ -0,0 +1,3 @@
+-- Content hash each stored PDF was rendered from; PDFs are regenerated when it changes
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS pdf_hash TEXT;
+ALTER TABLE bilty_pdf ADD COLUMN IF NOT EXISTS pdf_hash TEXT;
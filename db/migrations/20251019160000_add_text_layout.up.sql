@@ -0,0 +1,2 @@
+-- Field positions for plain-text (dot-matrix) bilty prints
+ALTER TABLE initial_setup ADD COLUMN IF NOT EXISTS text_layout JSONB;
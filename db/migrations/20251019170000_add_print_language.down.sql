@@ -0,0 +1 @@
+ALTER TABLE initial_setup DROP COLUMN IF EXISTS language;
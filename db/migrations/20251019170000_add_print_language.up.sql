@@ -0,0 +1,3 @@
+-- Default language of printed bilties: English, Hindi or both
+ALTER TABLE initial_setup ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT 'en'
+    CHECK (language IN ('en', 'hi', 'bilingual'));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bilty_history;
+DROP FUNCTION IF EXISTS bilty_history_append_only();
+ALTER TABLE bilty DROP COLUMN IF EXISTS updated_by;
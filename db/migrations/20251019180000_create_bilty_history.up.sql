@@ -0,0 +1,25 @@
+-- Who last edited a bilty
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS updated_by BIGINT REFERENCES app_user(id);
+
+-- Append-only audit trail of bilty changes. No foreign key to bilty, so the
+-- trail outlives a deleted bilty.
+CREATE TABLE IF NOT EXISTS bilty_history (
+    id BIGSERIAL PRIMARY KEY,
+    bilty_id BIGINT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'status', 'cancel', 'pdf')),
+    user_id BIGINT REFERENCES app_user(id),
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_bilty_history_bilty_id ON bilty_history(bilty_id, created_at);
+
+CREATE OR REPLACE FUNCTION bilty_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'bilty_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bilty_history_append_only ON bilty_history;
+CREATE TRIGGER bilty_history_append_only
+    BEFORE UPDATE OR DELETE ON bilty_history
+    FOR EACH ROW EXECUTE FUNCTION bilty_history_append_only();
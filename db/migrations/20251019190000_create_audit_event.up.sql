@@ -0,0 +1,28 @@
+-- Audit log of sensitive actions outside bilties: setup changes, signups,
+-- logins and PDF deletions. Rows are only ever removed by the retention purge.
+CREATE TABLE IF NOT EXISTS audit_event (
+    id BIGSERIAL PRIMARY KEY,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL DEFAULT '',
+    actor_id BIGINT REFERENCES app_user(id) ON DELETE SET NULL,
+    actor_name TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    payload JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_event_created_at ON audit_event(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_event_entity ON audit_event(entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_event_actor_id ON audit_event(actor_id);
+
+CREATE OR REPLACE FUNCTION audit_event_no_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event rows cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_event_no_update ON audit_event;
+CREATE TRIGGER audit_event_no_update
+    BEFORE UPDATE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_no_update();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_goods_bilty_seq;
+ALTER TABLE goods DROP COLUMN IF EXISTS seq;
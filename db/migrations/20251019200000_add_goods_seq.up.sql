@@ -0,0 +1,12 @@
+-- Line position of goods on their bilty, so lines keep their order and IDs
+-- when a bilty is edited
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS seq INTEGER NOT NULL DEFAULT 0;
+
+UPDATE goods g SET seq = numbered.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY bilty_id ORDER BY id) AS seq
+    FROM goods
+) numbered
+WHERE g.id = numbered.id;
+
+CREATE INDEX IF NOT EXISTS idx_goods_bilty_seq ON goods(bilty_id, seq);
@@ -0,0 +1,2 @@
+ALTER TABLE goods DROP COLUMN IF EXISTS commodity_id;
+DROP TABLE IF EXISTS commodity;
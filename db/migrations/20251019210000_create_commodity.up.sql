@@ -0,0 +1,16 @@
+-- Goods catalogue, so the same item isn't typed three different ways
+CREATE TABLE IF NOT EXISTS commodity (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    hsn_code TEXT,
+    per TEXT,
+    default_rate NUMERIC(10,2),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commodity_name ON commodity(lower(name));
+CREATE INDEX IF NOT EXISTS idx_commodity_hsn_code ON commodity(hsn_code);
+
+-- Goods lines keep their printed particulars and point at the catalogue entry
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS commodity_id BIGINT REFERENCES commodity(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_goods_commodity_id ON goods(commodity_id);
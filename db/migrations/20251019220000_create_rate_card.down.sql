@@ -0,0 +1,2 @@
+ALTER TABLE bilty DROP COLUMN IF EXISTS below_contract;
+DROP TABLE IF EXISTS rate_card;
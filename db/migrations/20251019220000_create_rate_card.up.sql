@@ -0,0 +1,22 @@
+-- Contracted tariffs. A NULL party, commodity or empty route end matches any,
+-- so a card with none of them is the default rate.
+CREATE TABLE IF NOT EXISTS rate_card (
+    id BIGSERIAL PRIMARY KEY,
+    party_id BIGINT REFERENCES company(id) ON DELETE CASCADE,
+    from_location TEXT NOT NULL DEFAULT '',
+    to_location TEXT NOT NULL DEFAULT '',
+    commodity_id BIGINT REFERENCES commodity(id) ON DELETE CASCADE,
+    per TEXT NOT NULL CHECK (per IN ('kg', 'quintal', 'tonne', 'pkt', 'fixed')),
+    slabs JSONB NOT NULL, -- [{"up_to_kg": 500, "rate": 2.50}, {"rate": 2.00}]
+    min_charge NUMERIC(12,2),
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP,
+    CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+CREATE INDEX IF NOT EXISTS idx_rate_card_party_id ON rate_card(party_id);
+CREATE INDEX IF NOT EXISTS idx_rate_card_validity ON rate_card(valid_from, valid_to);
+
+-- Set when a goods line is charged less than its rate card
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS below_contract BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE bilty DROP COLUMN IF EXISTS place_of_supply_code;
+ALTER TABLE bilty DROP COLUMN IF EXISTS place_of_supply;
@@ -0,0 +1,3 @@
+-- GST place of supply, derived from the station master when a bilty is saved
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS place_of_supply TEXT;
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS place_of_supply_code CHAR(2);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bilty_from_location_date;
+ALTER TABLE bilty DROP COLUMN IF EXISTS gst_amount;
+ALTER TABLE bilty DROP COLUMN IF EXISTS payment_type;
@@ -0,0 +1,7 @@
+-- How the freight is paid, and the GST charged on it. Existing bilties are to-pay.
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS payment_type TEXT NOT NULL DEFAULT 'to_pay'
+    CHECK (payment_type IN ('to_pay', 'paid', 'tbb'));
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS gst_amount NUMERIC(12,2);
+
+-- Booking register grouped by branch
+CREATE INDEX IF NOT EXISTS idx_bilty_from_location_date ON bilty(from_location, date);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tally_ledger;
+DROP INDEX IF EXISTS idx_bilty_tally_pending;
+ALTER TABLE bilty DROP COLUMN IF EXISTS tally_exported_at;
@@ -0,0 +1,10 @@
+-- Bilties already sent to Tally, so an export never includes them twice
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS tally_exported_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_bilty_tally_pending ON bilty(date) WHERE tally_exported_at IS NULL;
+
+-- Tally ledger names of parties; others are exported under their company name
+CREATE TABLE IF NOT EXISTS tally_ledger (
+    company_id BIGINT PRIMARY KEY REFERENCES company(id) ON DELETE CASCADE,
+    ledger_name TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
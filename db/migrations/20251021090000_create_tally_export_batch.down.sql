@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bilty_tally_export;
+ALTER TABLE bilty DROP COLUMN IF EXISTS tally_export_id;
+DROP TABLE IF EXISTS tally_export;
@@ -0,0 +1,11 @@
+-- Each Tally export is kept as a batch so its file can be downloaded again
+CREATE TABLE IF NOT EXISTS tally_export (
+    id BIGSERIAL PRIMARY KEY,
+    date_from DATE NOT NULL,
+    date_to DATE NOT NULL,
+    exported_at TIMESTAMP NOT NULL,
+    bilties INT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE bilty ADD COLUMN IF NOT EXISTS tally_export_id BIGINT REFERENCES tally_export(id);
+CREATE INDEX IF NOT EXISTS idx_bilty_tally_export ON bilty(tally_export_id) WHERE tally_export_id IS NOT NULL;
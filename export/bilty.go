@@ -0,0 +1,160 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// Goods line layouts
+const (
+	GoodsNone  = "none"
+	GoodsFlat  = "flat"  // a row per goods line, the bilty columns repeated
+	GoodsSheet = "sheet" // goods lines on a second sheet keyed by bilty number
+)
+
+func companyName(c *models.Company) interface{} {
+	if c == nil {
+		return nil
+	}
+	return c.Name
+}
+
+func companyGSTIN(c *models.Company) interface{} {
+	if c == nil {
+		return nil
+	}
+	return c.GSTIN
+}
+
+func packages(b *models.Bilty) int64 {
+	var n int64
+	for _, g := range b.Goods {
+		n += int64(g.NumOfPkts)
+	}
+	return n
+}
+
+func weight(b *models.Bilty) float64 {
+	var kg float64
+	for _, g := range b.Goods {
+		if g.WeightKG != nil {
+			kg += *g.WeightKG
+		}
+	}
+	return kg
+}
+
+// BiltyFields are the columns a bilty listing can export
+var BiltyFields = []Field[*models.Bilty]{
+	{Column{"bilty_no", "Bilty No", Int}, func(b *models.Bilty) interface{} { return b.BiltyNo }, true},
+	{Column{"date", "Date", Date}, func(b *models.Bilty) interface{} { return b.Date }, true},
+	{Column{"status", "Status", Text}, func(b *models.Bilty) interface{} { return b.Status }, false},
+	{Column{"payment_type", "Payment", Text}, func(b *models.Bilty) interface{} { return b.PaymentType }, true},
+	{Column{"from_location", "From", Text}, func(b *models.Bilty) interface{} { return b.FromLocation }, true},
+	{Column{"to_location", "To", Text}, func(b *models.Bilty) interface{} { return b.ToLocation }, true},
+	{Column{"consignor", "Consignor", Text}, func(b *models.Bilty) interface{} { return companyName(b.ConsignorCompany) }, true},
+	{Column{"consignor_gstin", "Consignor GSTIN", Text}, func(b *models.Bilty) interface{} { return companyGSTIN(b.ConsignorCompany) }, false},
+	{Column{"consignee", "Consignee", Text}, func(b *models.Bilty) interface{} { return companyName(b.ConsigneeCompany) }, true},
+	{Column{"consignee_gstin", "Consignee GSTIN", Text}, func(b *models.Bilty) interface{} { return companyGSTIN(b.ConsigneeCompany) }, false},
+	{Column{"inv_no", "Invoice No", Text}, func(b *models.Bilty) interface{} { return b.InvNo }, false},
+	{Column{"pvt_marks", "Pvt Marks", Text}, func(b *models.Bilty) interface{} { return b.PVTMarks }, false},
+	{Column{"permit_no", "Permit No", Text}, func(b *models.Bilty) interface{} { return b.PermitNo }, false},
+	{Column{"value", "Goods Value", Amount}, func(b *models.Bilty) interface{} { return b.ValueRupees }, false},
+	{Column{"packages", "Packages", Int}, func(b *models.Bilty) interface{} { return packages(b) }, true},
+	{Column{"weight_kg", "Weight (kg)", Number}, func(b *models.Bilty) interface{} { return weight(b) }, true},
+	{Column{"freight", "Freight", Amount}, func(b *models.Bilty) interface{} { return b.Freight() }, true},
+	{Column{"hamali", "Hamali", Amount}, func(b *models.Bilty) interface{} { return b.Hamali }, true},
+	{Column{"dd_charges", "DD Charges", Amount}, func(b *models.Bilty) interface{} { return b.DDCharges }, true},
+	{Column{"fov", "FOV", Amount}, func(b *models.Bilty) interface{} { return b.FOV }, true},
+	{Column{"other_charges", "Other Charges", Amount}, func(b *models.Bilty) interface{} { return b.OtherCharges }, true},
+	{Column{"gst", "GST", Amount}, func(b *models.Bilty) interface{} { return b.GSTAmount }, true},
+	{Column{"total", "Total", Amount}, func(b *models.Bilty) interface{} { return b.ToPay }, true},
+	{Column{"place_of_supply", "Place of Supply", Text}, func(b *models.Bilty) interface{} { return b.PlaceOfSupply }, false},
+	{Column{"place_of_supply_code", "State Code", Text}, func(b *models.Bilty) interface{} { return b.PlaceOfSupplyCode }, false},
+	{Column{"delivery_status", "Delivery", Text}, func(b *models.Bilty) interface{} { return b.DeliveryStatus }, false},
+	{Column{"remarks", "Remarks", Text}, func(b *models.Bilty) interface{} { return b.Remarks }, false},
+	{Column{"created_by", "Booked By", Text}, func(b *models.Bilty) interface{} {
+		if b.CreatedByUser == nil {
+			return nil
+		}
+		return b.CreatedByUser.Name
+	}, false},
+}
+
+// GoodsFields are the columns of a goods line
+var GoodsFields = []Field[*models.Goods]{
+	{Column{"seq", "Line", Int}, func(g *models.Goods) interface{} { return g.Seq }, true},
+	{Column{"particulars", "Particulars", Text}, func(g *models.Goods) interface{} { return g.Particulars }, true},
+	{Column{"num_of_pkts", "Pkts", Int}, func(g *models.Goods) interface{} { return g.NumOfPkts }, true},
+	{Column{"weight_kg", "Line Weight (kg)", Number}, func(g *models.Goods) interface{} { return g.WeightKG }, true},
+	{Column{"rate", "Rate", Amount}, func(g *models.Goods) interface{} { return g.Rate }, true},
+	{Column{"per", "Per", Text}, func(g *models.Goods) interface{} { return g.Per }, true},
+	{Column{"amount", "Amount", Amount}, func(g *models.Goods) interface{} { return g.Amount }, true},
+}
+
+// goodsKeys identify a bilty on the separate goods sheet
+var goodsKeys = []Field[*models.Bilty]{BiltyFields[0], BiltyFields[1]}
+
+// BiltyWriter writes bilties one at a time as they are read
+type BiltyWriter struct {
+	fields []Field[*models.Bilty]
+	goods  []Field[*models.Goods]
+	layout string
+	main   Sheet
+	lines  Sheet
+	row    []interface{}
+}
+
+// NewBiltyWriter starts the bilty sheet, and the goods sheet for the sheet
+// layout
+func NewBiltyWriter(wb Workbook, fields []Field[*models.Bilty], goods []Field[*models.Goods], layout string) (*BiltyWriter, error) {
+	w := &BiltyWriter{fields: fields, goods: goods, layout: layout}
+
+	cols := Columns(fields)
+	switch layout {
+	case GoodsNone, GoodsSheet:
+	case GoodsFlat:
+		cols = append(cols, Columns(goods)...)
+	default:
+		return nil, fmt.Errorf("unknown goods layout %q", layout)
+	}
+
+	var err error
+	if w.main, err = wb.AddSheet("Bilties", cols); err != nil {
+		return nil, err
+	}
+	if layout == GoodsSheet {
+		if w.lines, err = wb.AddSheet("Goods", append(Columns(goodsKeys), Columns(goods)...)); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Write adds a bilty to the export
+func (w *BiltyWriter) Write(b *models.Bilty) error {
+	if w.layout == GoodsFlat && len(b.Goods) > 0 {
+		for i := range b.Goods {
+			w.row = Values(Values(w.row[:0], w.fields, b), w.goods, &b.Goods[i])
+			if err := w.main.WriteRow(w.row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w.row = Values(w.row[:0], w.fields, b)
+	if err := w.main.WriteRow(w.row); err != nil {
+		return err
+	}
+	if w.layout == GoodsSheet {
+		for i := range b.Goods {
+			w.row = Values(Values(w.row[:0], goodsKeys, b), w.goods, &b.Goods[i])
+			if err := w.lines.WriteRow(w.row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWorkbook struct {
+	w     *csv.Writer
+	opts  Options
+	sheet *csvSheet
+}
+
+type csvSheet struct {
+	book *csvWorkbook
+	cols []Column
+	buf  []string
+}
+
+func newCSV(w io.Writer, opts Options) *csvWorkbook {
+	return &csvWorkbook{w: csv.NewWriter(w), opts: opts}
+}
+
+func (b *csvWorkbook) AddSheet(name string, cols []Column) (Sheet, error) {
+	if b.sheet != nil {
+		return nil, ErrSingleSheet
+	}
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.Header
+	}
+	if err := b.w.Write(header); err != nil {
+		return nil, err
+	}
+	b.sheet = &csvSheet{book: b, cols: cols, buf: make([]string, len(cols))}
+	return b.sheet, nil
+}
+
+func (s *csvSheet) WriteRow(values []interface{}) error {
+	for i := range s.buf {
+		s.buf[i] = ""
+		if i < len(values) {
+			s.buf[i] = formatValue(values[i], s.book.opts)
+		}
+	}
+	return s.book.w.Write(s.buf)
+}
+
+func (b *csvWorkbook) Close() error {
+	b.w.Flush()
+	return b.w.Error()
+}
@@ -0,0 +1,175 @@
+// Package export writes tables as CSV or XLSX one row at a time, so large
+// bilty ranges stream to the client instead of being built in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// File formats
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// Kind says how a column's values are formatted
+type Kind int
+
+const (
+	Text Kind = iota
+	Int
+	Number
+	Amount // models.Money
+	Date
+)
+
+// Column is one column of a sheet
+type Column struct {
+	Key    string
+	Header string
+	Kind   Kind
+}
+
+// Options control how values are written
+type Options struct {
+	// Indian groups digits the Indian way, 1,23,45,678.00, instead of
+	// writing plain numbers
+	Indian bool
+}
+
+// Workbook is a file being written. A CSV workbook holds a single sheet.
+type Workbook interface {
+	// AddSheet starts a sheet and writes its header row
+	AddSheet(name string, cols []Column) (Sheet, error)
+	// Close finishes the file; it does not close the underlying writer
+	Close() error
+}
+
+// Sheet takes rows of values in column order: strings, integers, float64,
+// models.Money, time.Time, pointers to those, or nil for an empty cell
+type Sheet interface {
+	WriteRow(values []interface{}) error
+}
+
+// ErrSingleSheet is returned when a second sheet is added to a CSV file
+var ErrSingleSheet = errors.New("csv files hold a single sheet")
+
+// New starts a workbook in the given format on w
+func New(w io.Writer, format string, opts Options) (Workbook, error) {
+	switch format {
+	case CSV:
+		return newCSV(w, opts), nil
+	case XLSX:
+		return newXLSX(w, opts), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ContentType is the MIME type of a format
+func ContentType(format string) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// deref unwraps the pointer values rows may carry; nil pointers become nil
+func deref(v interface{}) interface{} {
+	switch p := v.(type) {
+	case *string:
+		if p != nil {
+			return *p
+		}
+	case *int64:
+		if p != nil {
+			return *p
+		}
+	case *float64:
+		if p != nil {
+			return *p
+		}
+	case *models.Money:
+		if p != nil {
+			return *p
+		}
+	case *time.Time:
+		if p != nil {
+			return *p
+		}
+	default:
+		return v
+	}
+	return nil
+}
+
+// formatValue renders a value as text, for CSV cells
+func formatValue(v interface{}, opts Options) string {
+	switch x := deref(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return formatInt(int64(x), opts)
+	case int64:
+		return formatInt(x, opts)
+	case float64:
+		s := strconv.FormatFloat(x, 'f', -1, 64)
+		if opts.Indian {
+			return groupNumber(s)
+		}
+		return s
+	case models.Money:
+		if opts.Indian {
+			return groupNumber(x.String())
+		}
+		return x.String()
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format("02-01-2006")
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func formatInt(n int64, opts Options) string {
+	s := strconv.FormatInt(n, 10)
+	if opts.Indian {
+		return groupNumber(s)
+	}
+	return s
+}
+
+// groupNumber puts Indian digit grouping into a plain decimal number: the
+// last three digits, then pairs, so 12345678.5 becomes 1,23,45,678.5
+func groupNumber(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i:]
+	}
+	if len(whole) <= 3 {
+		return sign + whole + frac
+	}
+
+	head, tail := whole[:len(whole)-3], whole[len(whole)-3:]
+	var parts []string
+	for len(head) > 2 {
+		parts = append([]string{head[len(head)-2:]}, parts...)
+		head = head[:len(head)-2]
+	}
+	parts = append([]string{head}, parts...)
+	return sign + strings.Join(parts, ",") + "," + tail + frac
+}
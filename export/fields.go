@@ -0,0 +1,73 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field is an exportable column of a record type
+type Field[T any] struct {
+	Column
+	Value   func(T) interface{}
+	Default bool // exported when no columns are asked for
+}
+
+// Pick selects fields by key, in the order given. With no keys it returns the
+// default fields.
+func Pick[T any](fields []Field[T], keys []string) ([]Field[T], error) {
+	if len(keys) == 0 {
+		var out []Field[T]
+		for _, f := range fields {
+			if f.Default {
+				out = append(out, f)
+			}
+		}
+		return out, nil
+	}
+
+	byKey := map[string]Field[T]{}
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	var out []Field[T]
+	for _, k := range keys {
+		f, ok := byKey[strings.TrimSpace(k)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", k)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Columns lists the sheet columns of fields
+func Columns[T any](fields []Field[T]) []Column {
+	cols := make([]Column, len(fields))
+	for i, f := range fields {
+		cols[i] = f.Column
+	}
+	return cols
+}
+
+// Values reads a record's values for fields, appending them to row
+func Values[T any](row []interface{}, fields []Field[T], rec T) []interface{} {
+	for _, f := range fields {
+		row = append(row, f.Value(rec))
+	}
+	return row
+}
+
+// WriteAll writes a sheet of records, for results already in memory such
+// as report totals
+func WriteAll[T any](wb Workbook, name string, fields []Field[T], recs []T) error {
+	sheet, err := wb.AddSheet(name, Columns(fields))
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		if err := sheet.WriteRow(Values(nil, fields, rec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
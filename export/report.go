@@ -0,0 +1,69 @@
+package export
+
+import "github.com/hariomtransport/backend/models"
+
+// bookingTotalFields are the amounts every booking register row carries
+var bookingTotalFields = []Field[models.BookingRow]{
+	{Column{"bilties", "Bilties", Int}, func(r models.BookingRow) interface{} { return r.Bilties }, true},
+	{Column{"packages", "Packages", Int}, func(r models.BookingRow) interface{} { return r.Packages }, true},
+	{Column{"weight_kg", "Weight (kg)", Number}, func(r models.BookingRow) interface{} { return r.WeightKG }, true},
+	{Column{"to_pay", "To Pay", Amount}, func(r models.BookingRow) interface{} { return r.ToPay }, true},
+	{Column{"paid", "Paid", Amount}, func(r models.BookingRow) interface{} { return r.Paid }, true},
+	{Column{"tbb", "TBB", Amount}, func(r models.BookingRow) interface{} { return r.TBB }, true},
+	{Column{"freight", "Freight", Amount}, func(r models.BookingRow) interface{} { return r.Freight }, true},
+	{Column{"hamali", "Hamali", Amount}, func(r models.BookingRow) interface{} { return r.Hamali }, true},
+	{Column{"dd_charges", "DD Charges", Amount}, func(r models.BookingRow) interface{} { return r.DDCharges }, true},
+	{Column{"fov", "FOV", Amount}, func(r models.BookingRow) interface{} { return r.FOV }, true},
+	{Column{"other_charges", "Other Charges", Amount}, func(r models.BookingRow) interface{} { return r.OtherCharges }, true},
+	{Column{"gst", "GST", Amount}, func(r models.BookingRow) interface{} { return r.GST }, true},
+	{Column{"total", "Total", Amount}, func(r models.BookingRow) interface{} { return r.Total }, true},
+}
+
+// BookingFields are the columns of a booking register grouped by groupBy:
+// its grouping columns followed by the totals
+func BookingFields(groupBy string) []Field[models.BookingRow] {
+	var keys []Field[models.BookingRow]
+	switch groupBy {
+	case models.GroupByDay:
+		keys = append(keys, Field[models.BookingRow]{Column{"day", "Date", Text}, func(r models.BookingRow) interface{} { return r.Day }, true})
+	case models.GroupByBranch:
+		keys = append(keys, Field[models.BookingRow]{Column{"from_location", "Branch", Text}, func(r models.BookingRow) interface{} { return r.FromLocation }, true})
+	case models.GroupByRoute:
+		keys = append(keys,
+			Field[models.BookingRow]{Column{"from_location", "From", Text}, func(r models.BookingRow) interface{} { return r.FromLocation }, true},
+			Field[models.BookingRow]{Column{"to_location", "To", Text}, func(r models.BookingRow) interface{} { return r.ToLocation }, true},
+		)
+	case models.GroupByParty:
+		keys = append(keys,
+			Field[models.BookingRow]{Column{"party_id", "Party ID", Int}, func(r models.BookingRow) interface{} { return r.PartyID }, false},
+			Field[models.BookingRow]{Column{"party_name", "Party", Text}, func(r models.BookingRow) interface{} { return r.PartyName }, true},
+		)
+	}
+	return append(keys, bookingTotalFields...)
+}
+
+// WriteBookingRegister writes the register's rows followed by a totals row
+func WriteBookingRegister(wb Workbook, reg *models.BookingRegister, fields []Field[models.BookingRow]) error {
+	totals := models.BookingRow{BookingTotals: reg.Totals}
+	switch reg.GroupBy {
+	case models.GroupByDay:
+		totals.Day = "Total"
+	case models.GroupByParty:
+		totals.PartyName = "Total"
+	default:
+		totals.FromLocation = "Total"
+	}
+	return WriteAll(wb, "Booking Register", fields, append(reg.Rows, totals))
+}
+
+// CommodityFields are the columns of the commodity report
+var CommodityFields = []Field[models.CommodityTotal]{
+	{Column{"commodity_id", "Commodity ID", Int}, func(t models.CommodityTotal) interface{} { return t.CommodityID }, false},
+	{Column{"name", "Commodity", Text}, func(t models.CommodityTotal) interface{} { return t.Name }, true},
+	{Column{"hsn_code", "HSN", Text}, func(t models.CommodityTotal) interface{} { return t.HSNCode }, true},
+	{Column{"bilties", "Bilties", Int}, func(t models.CommodityTotal) interface{} { return t.Bilties }, true},
+	{Column{"packages", "Packages", Int}, func(t models.CommodityTotal) interface{} { return t.Packages }, true},
+	{Column{"weight_kg", "Weight (kg)", Number}, func(t models.CommodityTotal) interface{} { return t.WeightKG }, true},
+	{Column{"tonnes", "Tonnes", Number}, func(t models.CommodityTotal) interface{} { return t.Tonnes }, true},
+	{Column{"freight", "Freight", Amount}, func(t models.CommodityTotal) interface{} { return t.Freight }, true},
+}
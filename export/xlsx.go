@@ -0,0 +1,345 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// xlsxWorkbook writes a minimal SpreadsheetML package. The first sheet
+// streams straight into the zip; later sheets are spooled to temp files,
+// since a zip entry has to be written in one go, and copied in on Close.
+type xlsxWorkbook struct {
+	zip    *zip.Writer
+	opts   Options
+	sheets []*xlsxSheet
+}
+
+type xlsxSheet struct {
+	name string
+	cols []Column
+	w    *bufio.Writer
+	file *os.File // spool file, nil for the first sheet
+	rows int
+	opts Options
+}
+
+// Cell styles, indexes into cellXfs in styles.xml
+const (
+	styleText = iota
+	styleHeader
+	styleInt
+	styleNumber
+	styleAmount
+	styleDate
+)
+
+func newXLSX(w io.Writer, opts Options) *xlsxWorkbook {
+	return &xlsxWorkbook{zip: zip.NewWriter(w), opts: opts}
+}
+
+func (b *xlsxWorkbook) AddSheet(name string, cols []Column) (Sheet, error) {
+	s := &xlsxSheet{name: sheetName(name, len(b.sheets)+1), cols: cols, opts: b.opts}
+	if len(b.sheets) == 0 {
+		entry, err := b.zip.Create("xl/worksheets/sheet1.xml")
+		if err != nil {
+			return nil, err
+		}
+		s.w = bufio.NewWriter(entry)
+	} else {
+		f, err := os.CreateTemp("", "export-sheet-*.xml")
+		if err != nil {
+			return nil, err
+		}
+		s.file = f
+		s.w = bufio.NewWriter(f)
+	}
+	b.sheets = append(b.sheets, s)
+
+	if err := s.writeStart(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sheetName makes a name Excel accepts: at most 31 characters, none of []:*?/\
+func sheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", n)
+	}
+	return name
+}
+
+// writeStart writes the sheet up to the header row, which stays frozen
+func (s *xlsxSheet) writeStart() error {
+	s.w.WriteString(xml.Header)
+	s.w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	s.w.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(s.cols) > 0 {
+		s.w.WriteString(`<cols>`)
+		for i, c := range s.cols {
+			fmt.Fprintf(s.w, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, columnWidth(c))
+		}
+		s.w.WriteString(`</cols>`)
+	}
+	s.w.WriteString(`<sheetData>`)
+
+	header := make([]interface{}, len(s.cols))
+	for i, c := range s.cols {
+		header[i] = c.Header
+	}
+	return s.writeRow(header, true)
+}
+
+func columnWidth(c Column) int {
+	w := len(c.Header) + 2
+	switch c.Kind {
+	case Text:
+		w = max(w, 18)
+	case Date, Amount:
+		w = max(w, 13)
+	default:
+		w = max(w, 10)
+	}
+	return min(w, 50)
+}
+
+func (s *xlsxSheet) WriteRow(values []interface{}) error {
+	return s.writeRow(values, false)
+}
+
+func (s *xlsxSheet) writeRow(values []interface{}, header bool) error {
+	s.rows++
+	fmt.Fprintf(s.w, `<row r="%d">`, s.rows)
+	for i, v := range values {
+		if i >= len(s.cols) {
+			break
+		}
+		ref := cellRef(i, s.rows)
+		if header {
+			s.writeString(ref, fmt.Sprint(v), styleHeader)
+			continue
+		}
+		s.writeCell(ref, deref(v))
+	}
+	_, err := s.w.WriteString(`</row>`)
+	return err
+}
+
+func (s *xlsxSheet) writeCell(ref string, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+	case string:
+		if x != "" {
+			s.writeString(ref, x, styleText)
+		}
+	case int:
+		fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%d</v></c>`, ref, styleInt, x)
+	case int64:
+		fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%d</v></c>`, ref, styleInt, x)
+	case float64:
+		if !math.IsNaN(x) && !math.IsInf(x, 0) {
+			fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleNumber, strconv.FormatFloat(x, 'f', -1, 64))
+		}
+	case models.Money:
+		fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleAmount, x.String())
+	case time.Time:
+		if !x.IsZero() {
+			fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%d</v></c>`, ref, styleDate, excelDate(x))
+		}
+	default:
+		s.writeString(ref, fmt.Sprint(x), styleText)
+	}
+}
+
+func (s *xlsxSheet) writeString(ref, text string, style int) {
+	fmt.Fprintf(s.w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+	xml.EscapeText(s.w, []byte(text))
+	s.w.WriteString(`</t></is></c>`)
+}
+
+// cellRef names a cell, e.g. column 27 of row 3 is AB3
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// excelDate is the spreadsheet serial number of t's calendar day
+func excelDate(t time.Time) int64 {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return int64(day.Sub(epoch).Hours() / 24)
+}
+
+func (s *xlsxSheet) finish() error {
+	s.w.WriteString(`</sheetData></worksheet>`)
+	return s.w.Flush()
+}
+
+func (b *xlsxWorkbook) Close() error {
+	defer b.removeSpools()
+
+	if len(b.sheets) == 0 {
+		if _, err := b.AddSheet("Sheet1", nil); err != nil {
+			return err
+		}
+	}
+	if err := b.sheets[0].finish(); err != nil {
+		return err
+	}
+	for i, s := range b.sheets[1:] {
+		if err := s.finish(); err != nil {
+			return err
+		}
+		if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		entry, err := b.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+2))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(entry, s.file); err != nil {
+			return err
+		}
+	}
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", b.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", b.workbook()},
+		{"xl/_rels/workbook.xml.rels", b.workbookRels()},
+		{"xl/styles.xml", styles(b.opts)},
+	}
+	for _, p := range parts {
+		entry, err := b.zip.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, p.body); err != nil {
+			return err
+		}
+	}
+	return b.zip.Close()
+}
+
+func (b *xlsxWorkbook) removeSpools() {
+	for _, s := range b.sheets {
+		if s.file != nil {
+			s.file.Close()
+			os.Remove(s.file.Name())
+		}
+	}
+}
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func (b *xlsxWorkbook) contentTypes() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range b.sheets {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func (b *xlsxWorkbook) workbook() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range b.sheets {
+		sb.WriteString(`<sheet name="`)
+		xml.EscapeText(&sb, []byte(s.name))
+		fmt.Fprintf(&sb, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func (b *xlsxWorkbook) workbookRels() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range b.sheets {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(b.sheets)+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// Number formats. The Indian ones group lakhs and crores with conditional
+// sections, since Excel formats only group in thousands.
+const (
+	fmtIndianInt    = `[>=10000000]##\,##\,##\,##0;[>=100000]##\,##\,##0;##,##0`
+	fmtIndianAmount = `[>=10000000]##\,##\,##\,##0.00;[>=100000]##\,##\,##0.00;##,##0.00`
+	fmtDate         = `dd\-mm\-yyyy`
+)
+
+// styles builds styles.xml with one cellXfs entry per style constant
+func styles(opts Options) string {
+	// Built-in formats: 0 General, 1 "0", 2 "0.00"
+	intFmt, numberFmt, amountFmt := 1, 0, 2
+	if opts.Indian {
+		intFmt, numberFmt, amountFmt = 164, 0, 165
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<numFmts count="3">`)
+	for _, f := range []struct {
+		id   int
+		code string
+	}{{164, fmtIndianInt}, {165, fmtIndianAmount}, {166, fmtDate}} {
+		fmt.Fprintf(&sb, `<numFmt numFmtId="%d" formatCode="`, f.id)
+		xml.EscapeText(&sb, []byte(f.code))
+		sb.WriteString(`"/>`)
+	}
+	sb.WriteString(`</numFmts>`)
+	sb.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`)
+	sb.WriteString(`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>`)
+	sb.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	sb.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+
+	xfs := []struct{ fmtID, fontID int }{
+		styleText:   {0, 0},
+		styleHeader: {0, 1},
+		styleInt:    {intFmt, 0},
+		styleNumber: {numberFmt, 0},
+		styleAmount: {amountFmt, 0},
+		styleDate:   {166, 0},
+	}
+	fmt.Fprintf(&sb, `<cellXfs count="%d">`, len(xfs))
+	for _, xf := range xfs {
+		fmt.Fprintf(&sb, `<xf numFmtId="%d" fontId="%d" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>`, xf.fmtID, xf.fontID)
+	}
+	sb.WriteString(`</cellXfs>`)
+	sb.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`)
+	sb.WriteString(`</styleSheet>`)
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+)
+
+type AuditHandler struct {
+	Repo repository.AuditRepository
+}
+
+// Page sizes for the audit log
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// ListAuditEvents handler returns audit events newest first, filtered by
+// action, entity, entity_id, actor_id, from and to (YYYY-MM-DD, both
+// inclusive) and paged with limit and offset
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.Repo.ListEvents(q)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch audit events: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Audit events fetched successfully",
+		Data:    page,
+	})
+}
+
+func parseAuditQuery(r *http.Request) (*models.AuditQuery, error) {
+	v := r.URL.Query()
+	q := &models.AuditQuery{
+		Action:   models.AuditAction(v.Get("action")),
+		Entity:   v.Get("entity"),
+		EntityID: v.Get("entity_id"),
+		Limit:    defaultAuditLimit,
+	}
+
+	if s := v.Get("actor_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid actor_id")
+		}
+		q.ActorID = &id
+	}
+
+	if s := v.Get("from"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from, expected YYYY-MM-DD")
+		}
+		q.From = &t
+	}
+	if s := v.Get("to"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to, expected YYYY-MM-DD")
+		}
+		end := t.AddDate(0, 0, 1) // include the whole day
+		q.To = &end
+	}
+
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid limit")
+		}
+		q.Limit = min(n, maxAuditLimit)
+	}
+	if s := v.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid offset")
+		}
+		q.Offset = n
+	}
+
+	return q, nil
+}
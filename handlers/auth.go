@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hariomtransport/backend/utils"
+)
+
+// RequireRole wraps a handler so it only runs for requests carrying a valid
+// bearer token. When roles are given, the token's role must be one of them.
+func RequireRole(tokens *utils.TokenManager, roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			claims, err := tokens.Verify(token)
+			if err != nil {
+				writeJSON(w, http.StatusUnauthorized, ApiResponse{
+					Success: false,
+					Message: "Unauthorized",
+				})
+				return
+			}
+
+			if len(roles) > 0 {
+				allowed := false
+				for _, role := range roles {
+					if claims.Role == role {
+						allowed = true
+						break
+					}
+				}
+				if !allowed {
+					writeJSON(w, http.StatusForbidden, ApiResponse{
+						Success: false,
+						Message: "Forbidden",
+					})
+					return
+				}
+			}
+
+			next(w, r.WithContext(utils.WithClaims(r.Context(), claims)))
+		}
+	}
+}
+
+// CurrentUser returns the claims of the authenticated user, or nil
+func CurrentUser(r *http.Request) *utils.TokenClaims {
+	return utils.ClaimsFromContext(r.Context())
+}
+
+// OptionalAuth attaches the user's claims when the request carries a valid
+// bearer token, and lets anonymous requests through unchanged
+func OptionalAuth(tokens *utils.TokenManager) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if claims, err := tokens.Verify(token); err == nil {
+				r = r.WithContext(utils.WithClaims(r.Context(), claims))
+			}
+			next(w, r)
+		}
+	}
+}
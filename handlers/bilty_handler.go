@@ -7,6 +7,7 @@ import (
 
 	"github.com/hariomtransport/backend/models"
 	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/stations"
 )
 
 // Response structure for consistent API responses
@@ -17,7 +18,8 @@ type ApiResponse struct {
 }
 
 type BiltyHandler struct {
-	Repo repository.BiltyRepository
+	Repo     repository.BiltyRepository
+	Stations *stations.Directory // canonical locations and place of supply; optional
 }
 
 // Helper to write JSON responses
@@ -38,6 +40,28 @@ func (h *BiltyHandler) CreateBilty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if bilty.PaymentType != "" && !models.ValidPaymentType(bilty.PaymentType) {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid payment type: must be to_pay, paid or tbb",
+		})
+		return
+	}
+
+	// Changes are recorded against the signed-in user
+	if user := CurrentUser(r); user != nil {
+		if bilty.ID == 0 && bilty.CreatedBy == 0 {
+			bilty.CreatedBy = user.UserID
+		}
+		if bilty.ID != 0 {
+			bilty.UpdatedBy = &user.UserID
+		}
+	}
+
+	if h.Stations != nil {
+		h.Stations.NormalizeBilty(&bilty)
+	}
+
 	if err := h.Repo.CreateBiltyWithParties(&bilty); err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
 			Success: false,
@@ -123,6 +147,36 @@ func (h *BiltyHandler) GetBiltyByID(w http.ResponseWriter, r *http.Request, id s
 	})
 }
 
+// GetBiltyHistory handler returns the bilty's audit trail, oldest first
+func (h *BiltyHandler) GetBiltyHistory(w http.ResponseWriter, r *http.Request, id string) {
+	biltyID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid bilty ID",
+		})
+		return
+	}
+
+	history, err := h.Repo.BiltyHistory(biltyID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch bilty history: " + err.Error(),
+		})
+		return
+	}
+	if history == nil {
+		history = []models.BiltyHistory{}
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Bilty history fetched successfully",
+		Data:    history,
+	})
+}
+
 // DeleteBilty handler
 func (h *BiltyHandler) DeleteBilty(w http.ResponseWriter, r *http.Request) {
 	biltyIDStr := r.URL.Query().Get("id")
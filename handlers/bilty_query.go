@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// parseBiltyQuery builds a BiltyQuery from URL parameters:
+// ids (comma separated), date (single day), date_from, date_to (YYYY-MM-DD),
+// status, from_location, to_location, consignor_company_id, consignee_company_id
+func parseBiltyQuery(r *http.Request) (*models.BiltyQuery, error) {
+	v := r.URL.Query()
+	q := &models.BiltyQuery{
+		Status:       v.Get("status"),
+		FromLocation: v.Get("from_location"),
+		ToLocation:   v.Get("to_location"),
+	}
+
+	if ids := v.Get("ids"); ids != "" {
+		for _, part := range strings.Split(ids, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bilty ID %q", part)
+			}
+			q.IDs = append(q.IDs, id)
+		}
+	}
+
+	parseDate := func(key string) (*time.Time, error) {
+		s := v.Get(key)
+		if s == "" {
+			return nil, nil
+		}
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s, expected YYYY-MM-DD", key)
+		}
+		return &t, nil
+	}
+
+	var err error
+	if q.DateFrom, err = parseDate("date_from"); err != nil {
+		return nil, err
+	}
+	if q.DateTo, err = parseDate("date_to"); err != nil {
+		return nil, err
+	}
+	day, err := parseDate("date")
+	if err != nil {
+		return nil, err
+	}
+	if day != nil {
+		q.DateFrom, q.DateTo = day, day
+	}
+
+	parseID := func(key string) (*int64, error) {
+		s := v.Get(key)
+		if s == "" {
+			return nil, nil
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s", key)
+		}
+		return &id, nil
+	}
+	if q.ConsignorCompanyID, err = parseID("consignor_company_id"); err != nil {
+		return nil, err
+	}
+	if q.ConsigneeCompanyID, err = parseID("consignee_company_id"); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
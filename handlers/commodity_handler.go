@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hariomtransport/backend/export"
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+)
+
+type CommodityHandler struct {
+	Repo repository.CommodityRepository
+}
+
+// Autocomplete result sizes
+const (
+	defaultCommodityLimit = 20
+	maxCommodityLimit     = 100
+)
+
+// SearchCommodities handler backs the particulars autocomplete: ?q= matches
+// names and HSN codes, names starting with it first. Without q it lists the
+// catalogue.
+func (h *CommodityHandler) SearchCommodities(w http.ResponseWriter, r *http.Request) {
+	limit := defaultCommodityLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, ApiResponse{
+				Success: false,
+				Message: "Invalid limit",
+			})
+			return
+		}
+		limit = min(n, maxCommodityLimit)
+	}
+
+	list, err := h.Repo.SearchCommodities(r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch commodities: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Commodities fetched successfully",
+		Data:    list,
+	})
+}
+
+// GetCommodity handler
+func (h *CommodityHandler) GetCommodity(w http.ResponseWriter, r *http.Request, id string) {
+	commodityID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid commodity ID",
+		})
+		return
+	}
+
+	c, err := h.Repo.GetCommodity(commodityID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch commodity: " + err.Error(),
+		})
+		return
+	}
+	if c == nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Commodity not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Commodity fetched successfully",
+		Data:    c,
+	})
+}
+
+// SaveCommodity handler creates a commodity, or updates it when the body has an ID
+func (h *CommodityHandler) SaveCommodity(w http.ResponseWriter, r *http.Request) {
+	var c models.Commodity
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Commodity name is required",
+		})
+		return
+	}
+	if c.DefaultRate != nil && *c.DefaultRate < 0 {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Default rate cannot be negative",
+		})
+		return
+	}
+
+	if err := h.Repo.SaveCommodity(&c); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to save commodity: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ApiResponse{
+		Success: true,
+		Message: "Commodity saved successfully",
+		Data:    c,
+	})
+}
+
+// CommodityReport handler totals packages, weight and freight per commodity
+// for the bilties matching the same filters as /bilty/pdf/bulk. format=csv or
+// xlsx downloads it as a spreadsheet.
+func (h *CommodityHandler) CommodityReport(w http.ResponseWriter, r *http.Request) {
+	q, err := parseBiltyQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	req, err := parseExport(r, "")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	totals, err := h.Repo.CommodityTotals(q)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to build commodity report: " + err.Error(),
+		})
+		return
+	}
+
+	if req != nil {
+		writeExport(w, req, "commodity_report", export.CommodityFields, func(wb export.Workbook, fields []export.Field[models.CommodityTotal]) error {
+			return export.WriteAll(wb, "Commodities", fields, totals)
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Commodity report fetched successfully",
+		Data:    totals,
+	})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/export"
+	"github.com/hariomtransport/backend/models"
+)
+
+// exportRequest holds the spreadsheet export parameters shared by listings
+// and reports: format (csv or xlsx), columns (comma separated keys, in order)
+// and numbers=plain to write numbers without Indian digit grouping
+type exportRequest struct {
+	Format  string
+	Columns []string
+	Options export.Options
+}
+
+// parseExport reads the export parameters, with def as the format when none
+// is given. It returns nil when the response should be JSON.
+func parseExport(r *http.Request, def string) (*exportRequest, error) {
+	v := r.URL.Query()
+	format := v.Get("format")
+	if format == "" {
+		format = def
+	}
+	if format == "" || format == "json" {
+		return nil, nil
+	}
+	if format != export.CSV && format != export.XLSX {
+		return nil, fmt.Errorf("format must be json, csv or xlsx")
+	}
+	return &exportRequest{
+		Format:  format,
+		Columns: splitList(v.Get("columns")),
+		Options: export.Options{Indian: v.Get("numbers") != "plain"},
+	}, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// startExport sends the download headers and opens the workbook. Rows stream
+// after this, so later failures can only be logged.
+func startExport(w http.ResponseWriter, req *exportRequest, name string) (export.Workbook, error) {
+	w.Header().Set("Content-Type", export.ContentType(req.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.%s"`, name, time.Now().Format("20060102_150405"), req.Format))
+	w.Header().Set("Cache-Control", "private, no-store")
+	return export.New(w, req.Format, req.Options)
+}
+
+// writeExport streams a sheet of records already in memory, such as report rows
+func writeExport[T any](w http.ResponseWriter, req *exportRequest, name string, fields []export.Field[T], write func(export.Workbook, []export.Field[T]) error) {
+	picked, err := export.Pick(fields, req.Columns)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	wb, err := startExport(w, req, name)
+	if err == nil {
+		err = write(wb, picked)
+		if cerr := wb.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Printf("⚠️ %s export aborted: %v", name, err)
+	}
+}
+
+// ExportBilties handler streams the bilties matching the query parameters as
+// CSV (default) or XLSX. goods=none (default), flat or sheet places the goods
+// lines, and goods_columns picks their columns.
+func (h *BiltyHandler) ExportBilties(w http.ResponseWriter, r *http.Request) {
+	q, err := parseBiltyQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if q.IsEmpty() {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Provide ids or a date range",
+		})
+		return
+	}
+
+	req, err := parseExport(r, export.CSV)
+	if err == nil && req == nil {
+		err = fmt.Errorf("format must be csv or xlsx")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	layout := r.URL.Query().Get("goods")
+	if layout == "" {
+		layout = export.GoodsNone
+	}
+	if layout != export.GoodsNone && layout != export.GoodsFlat && layout != export.GoodsSheet {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "goods must be none, flat or sheet",
+		})
+		return
+	}
+	if layout == export.GoodsSheet && req.Format != export.XLSX {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "A separate goods sheet needs format=xlsx",
+		})
+		return
+	}
+
+	fields, err := export.Pick(export.BiltyFields, req.Columns)
+	if err == nil && len(fields) == 0 {
+		err = fmt.Errorf("no columns selected")
+	}
+	var goods []export.Field[*models.Goods]
+	if err == nil {
+		goods, err = export.Pick(export.GoodsFields, splitList(r.URL.Query().Get("goods_columns")))
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	wb, err := startExport(w, req, "bilties")
+	if err == nil {
+		var bw *export.BiltyWriter
+		if bw, err = export.NewBiltyWriter(wb, fields, goods, layout); err == nil {
+			err = h.Repo.EachBilty(q, bw.Write)
+		}
+		if cerr := wb.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Printf("⚠️ Bilty export aborted: %v", err)
+	}
+}
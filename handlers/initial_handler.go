@@ -3,15 +3,21 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/hariomtransport/backend/models"
 	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/utils"
 )
 
 type InitialHandler struct {
-	Repo repository.InitialRepository
+	Repo  repository.InitialRepository
+	Audit *utils.Auditor
 }
 
+// setupIgnored are initial setup fields left out of the audited changes
+var setupIgnored = map[string]bool{"id": true, "created_at": true}
+
 // SaveInitial handler
 func (h *InitialHandler) SaveInitial(w http.ResponseWriter, r *http.Request) {
 	var initial models.InitialSetup
@@ -23,6 +29,30 @@ func (h *InitialHandler) SaveInitial(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := utils.ResolveLanguage(&initial, ""); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := utils.ValidateCopies(initial.Copies); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	before, err := h.Repo.GetInitial()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to load current initial setup: " + err.Error(),
+		})
+		return
+	}
+
 	if err := h.Repo.SaveInitial(&initial); err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
 			Success: false,
@@ -31,6 +61,13 @@ func (h *InitialHandler) SaveInitial(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.Audit.Record(r.Context(), &models.AuditEvent{
+		Action:   models.AuditSetupSaved,
+		Entity:   models.AuditEntitySetup,
+		EntityID: strconv.FormatInt(initial.ID, 10),
+		Payload:  map[string]interface{}{"changes": models.DiffJSON(before, &initial, setupIgnored)},
+	})
+
 	writeJSON(w, http.StatusCreated, ApiResponse{
 		Success: true,
 		Message: "Initial setup saved successfully",
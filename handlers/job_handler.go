@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/utils"
+)
+
+type JobHandler struct {
+	Repo      repository.JobRepository
+	Generator *utils.PDFGenerator
+}
+
+// GetJob handler returns the status of a background PDF job. The result of a
+// finished job is handed out as a fresh signed link.
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request, id string) {
+	jobID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid job ID",
+		})
+		return
+	}
+
+	job, err := h.Repo.GetJob(jobID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch job: " + err.Error(),
+		})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Job not found",
+		})
+		return
+	}
+
+	if job.Status == models.JobDone && job.Result != nil {
+		fileURL, _, err := h.Generator.SignedPDFURL(r.Context(), *job.Result)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ApiResponse{
+				Success: false,
+				Message: "Failed to sign PDF link: " + err.Error(),
+			})
+			return
+		}
+		job.Result = &fileURL
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Job fetched successfully",
+		Data:    job,
+	})
+}
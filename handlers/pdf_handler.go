@@ -1,32 +1,34 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
+	"github.com/hariomtransport/backend/models"
 	"github.com/hariomtransport/backend/repository"
 	"github.com/hariomtransport/backend/utils"
 )
 
 type PDFHandler struct {
-	Repo     *repository.PDFRepository
-	SavePath string
+	Repo      *repository.PDFRepository
+	Generator *utils.PDFGenerator
+	Jobs      *utils.PDFJobRunner
 }
 
-// BiltyPDF handles the API request to generate and save a Bilty PDF
-// BiltyPDF handles the API request to generate and save a Bilty PDF
-func (h *PDFHandler) BiltyPDF(w http.ResponseWriter, r *http.Request) {
-	// Parse bilty ID
+// biltyIDParam reads the required ?id parameter, writing the error response when it is bad
+func biltyIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
 	biltyIDStr := r.URL.Query().Get("id")
 	if biltyIDStr == "" {
 		writeJSON(w, http.StatusBadRequest, ApiResponse{
 			Success: false,
 			Message: "Missing bilty ID",
 		})
-		return
+		return 0, false
 	}
 
 	biltyID, err := strconv.ParseInt(biltyIDStr, 10, 64)
@@ -35,10 +37,150 @@ func (h *PDFHandler) BiltyPDF(w http.ResponseWriter, r *http.Request) {
 			Success: false,
 			Message: "Invalid bilty ID",
 		})
+		return 0, false
+	}
+	return biltyID, true
+}
+
+// writePublishError responds to a failed PublishBiltyPDF
+func writePublishError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrBiltyNotFound):
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Bilty not found",
+		})
+	case errors.Is(err, utils.ErrInvalidPDFOptions):
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	default:
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to generate PDF: " + err.Error(),
+		})
+	}
+}
+
+// BiltyPDF handles the API request to generate and save a Bilty PDF. It
+// responds with a signed link that expires; the PDF itself stays private.
+// With async=true the work is queued and a job is returned for polling.
+// With reprint=true an issued bilty is printed as a DUPLICATE, and profile
+// picks the layout and paper (a4, a5, thermal80). format=text or
+// format=escpos returns the bilty for dot-matrix or thermal printers instead.
+func (h *PDFHandler) BiltyPDF(w http.ResponseWriter, r *http.Request) {
+	biltyID, ok := biltyIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	opts := pdfOptions(r)
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "pdf":
+	case utils.FormatText, utils.FormatESCPOS:
+		h.printText(w, biltyID, opts, format)
+		return
+	default:
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Unknown format " + strconv.Quote(format) + ", expected pdf, text or escpos",
+		})
+		return
+	}
+
+	if async, _ := strconv.ParseBool(r.URL.Query().Get("async")); async {
+		h.submitJob(w, biltyID, opts)
+		return
+	}
+
+	location, fresh, err := h.Generator.PublishBiltyPDF(r.Context(), biltyID, opts)
+	if err != nil {
+		writePublishError(w, err)
+		return
+	}
+
+	fileURL, expiresAt, err := h.Generator.SignedPDFURL(r.Context(), location)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to sign PDF link: " + err.Error(),
+		})
+		return
+	}
+
+	message := "Existing PDF is up-to-date"
+	if fresh {
+		message = "Bilty PDF generated and uploaded successfully"
+	}
+
+	// Success response
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: message,
+		Data: map[string]interface{}{
+			"file":       fileURL,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
+// DownloadPDF streams the bilty PDF through the API. It is mounted behind
+// authentication, so unlike a signed link it never outlives the caller's access.
+func (h *PDFHandler) DownloadPDF(w http.ResponseWriter, r *http.Request) {
+	biltyID, ok := biltyIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	location, _, err := h.Generator.PublishBiltyPDF(r.Context(), biltyID, pdfOptions(r))
+	if err != nil {
+		writePublishError(w, err)
+		return
+	}
+
+	body, err := h.Generator.OpenPDF(r.Context(), location)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to read PDF: " + err.Error(),
+		})
 		return
 	}
+	defer body.Close()
 
-	// Fetch bilty record
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="bilty_%d.pdf"`, biltyID))
+	w.Header().Set("Cache-Control", "private, no-store")
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("⚠️ PDF download of bilty %d aborted: %v", biltyID, err)
+	}
+}
+
+// printText responds with the bilty rendered for a text printer
+func (h *PDFHandler) printText(w http.ResponseWriter, biltyID int64, opts models.PDFOptions, format string) {
+	out, err := h.Generator.PrintBiltyText(biltyID, opts, format)
+	if err != nil {
+		writePublishError(w, err)
+		return
+	}
+
+	if format == utils.FormatESCPOS {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="bilty_%d.bin"`, biltyID))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=us-ascii")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="bilty_%d.txt"`, biltyID))
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	if _, err := w.Write(out); err != nil {
+		log.Printf("⚠️ Text print of bilty %d aborted: %v", biltyID, err)
+	}
+}
+
+// submitJob queues PDF generation and responds with the job to poll
+func (h *PDFHandler) submitJob(w http.ResponseWriter, biltyID int64, opts models.PDFOptions) {
 	bilty, err := h.Repo.BiltyRepo.GetBiltyByID(biltyID)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
@@ -55,90 +197,144 @@ func (h *PDFHandler) BiltyPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine save directory
-	saveDir := h.SavePath
-	if saveDir == "" {
-		saveDir = "./pdfs"
-	}
-	if err := os.MkdirAll(saveDir, os.ModePerm); err != nil {
+	job, err := h.Jobs.Submit(biltyID, opts)
+	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
 			Success: false,
-			Message: "Failed to create save directory: " + err.Error(),
+			Message: "Failed to queue PDF job: " + err.Error(),
 		})
 		return
 	}
 
-	// Check if PDF needs regeneration
-	shouldGenerate := false
-	if bilty.PdfPath == nil ||
-		bilty.PdfCreatedAt == nil ||
-		(bilty.UpdatedAt != nil && bilty.PdfCreatedAt.Before(*bilty.UpdatedAt)) {
-		shouldGenerate = true
+	writeJSON(w, http.StatusAccepted, ApiResponse{
+		Success: true,
+		Message: "PDF generation queued",
+		Data: map[string]interface{}{
+			"job":        job,
+			"status_url": fmt.Sprintf("/jobs/%d", job.ID),
+		},
+	})
+}
+
+// pdfOptions reads the print options shared by the PDF endpoints
+func pdfOptions(r *http.Request) models.PDFOptions {
+	reprint, _ := strconv.ParseBool(r.URL.Query().Get("reprint"))
+	return models.PDFOptions{
+		Copies:   utils.ParseCopyKeys(r.URL.Query().Get("copies")),
+		Reprint:  reprint,
+		Profile:  r.URL.Query().Get("profile"),
+		Language: r.URL.Query().Get("lang"),
 	}
+}
 
-	// Reuse existing PDF if still valid
-	if !shouldGenerate {
-		writeJSON(w, http.StatusOK, ApiResponse{
-			Success: true,
-			Message: "Existing PDF is up-to-date",
-			Data: map[string]interface{}{
-				"file": *bilty.PdfPath,
-			},
-		})
-		return
+// ListProfiles handler returns the print profiles /bilty/pdf accepts
+func (h *PDFHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles := make([]utils.PrintProfile, 0, len(utils.PrintProfiles))
+	for _, name := range utils.ProfileNames() {
+		profiles = append(profiles, utils.PrintProfiles[name])
 	}
 
-	// Generate new PDF
-	pdfBytes, err := utils.GenerateBiltyPDF(h.Repo, biltyID)
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Print profiles fetched successfully",
+		Data:    profiles,
+	})
+}
+
+// maxBulkBilties caps how many bilties a single bulk download may render
+const maxBulkBilties = 500
+
+// BulkPDF renders every bilty matching the query as one merged PDF
+// (format=pdf, default) or a ZIP of individual PDFs (format=zip)
+func (h *PDFHandler) BulkPDF(w http.ResponseWriter, r *http.Request) {
+	q, err := parseBiltyQuery(r)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
 			Success: false,
-			Message: "Failed to generate PDF: " + err.Error(),
+			Message: err.Error(),
 		})
 		return
 	}
-	if len(pdfBytes) == 0 {
-		writeJSON(w, http.StatusNotFound, ApiResponse{
+	if q.IsEmpty() {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
 			Success: false,
-			Message: "No bilty data found to generate PDF",
+			Message: "Provide ids or a date range",
 		})
 		return
 	}
 
-	// Save or upload PDF
-	filename := fmt.Sprintf("bilty_%d_%d.pdf", biltyID, time.Now().Unix())
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "zip" {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "format must be pdf or zip",
+		})
+		return
+	}
 
-	// Upload PDF to Cloudflare R2
-	r2URL, err := utils.UploadToR2(pdfBytes, filename)
+	bilties, err := h.Repo.BiltyRepo.QueryBilties(q)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
 			Success: false,
-			Message: "Failed to upload PDF to R2: " + err.Error(),
+			Message: "Failed to fetch bilty records: " + err.Error(),
+		})
+		return
+	}
+	if len(bilties) == 0 {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "No bilty found for the given filter",
+		})
+		return
+	}
+	if len(bilties) > maxBulkBilties {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Too many bilties (%d), narrow the filter to at most %d", len(bilties), maxBulkBilties),
 		})
 		return
 	}
 
-	oldPdfPath := bilty.PdfPath
+	opts := pdfOptions(r)
+	name := fmt.Sprintf("bilties_%s", time.Now().Format("20060102_150405"))
 
-	// Update database
-	now := time.Now().UTC()
-	if err := h.Repo.BiltyRepo.UpdatePDFInfo(biltyID, r2URL, now); err != nil {
-		fmt.Printf("⚠️ Failed to update PDF info for bilty %d: %v\n", biltyID, err)
+	if err := h.Generator.ValidateOptions(opts); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, utils.ErrInvalidPDFOptions) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
 	}
 
-	// Delete old PDF from R2
-	if oldPdfPath != nil && *oldPdfPath != "" {
-		if err := utils.DeleteFromR2(*oldPdfPath); err != nil {
-			fmt.Printf("⚠️ Failed to delete old PDF from R2 for bilty %d: %v\n", biltyID, err)
+	if format == "zip" {
+		// Headers go out before rendering starts, so later failures can only be logged
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+		if err := h.Generator.WriteBulkZIP(r.Context(), w, bilties, opts); err != nil {
+			log.Printf("⚠️ Bulk ZIP download aborted: %v", err)
 		}
+		return
 	}
 
-	// Success response
-	writeJSON(w, http.StatusOK, ApiResponse{
-		Success: true,
-		Message: "Bilty PDF generated and uploaded successfully",
-		Data: map[string]interface{}{
-			"file": r2URL,
-		},
-	})
+	pdfs, err := h.Generator.RenderBulk(r.Context(), bilties, opts)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to generate PDF: " + err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, name))
+	if err := utils.MergePDFs(w, pdfs); err != nil {
+		log.Printf("⚠️ Bulk PDF download aborted: %v", err)
+	}
 }
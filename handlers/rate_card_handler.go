@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/stations"
+)
+
+type RateCardHandler struct {
+	Repo     repository.RateCardRepository
+	Stations *stations.Directory // optional; routes are matched on canonical names
+}
+
+// ListRateCards handler lists every rate card, or one party's with ?party_id=
+func (h *RateCardHandler) ListRateCards(w http.ResponseWriter, r *http.Request) {
+	var partyID *int64
+	if s := r.URL.Query().Get("party_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ApiResponse{
+				Success: false,
+				Message: "Invalid party ID",
+			})
+			return
+		}
+		partyID = &id
+	}
+
+	cards, err := h.Repo.ListRateCards(partyID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch rate cards: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Rate cards fetched successfully",
+		Data:    cards,
+	})
+}
+
+// SaveRateCard handler creates a rate card, or updates it when the body has an ID
+func (h *RateCardHandler) SaveRateCard(w http.ResponseWriter, r *http.Request) {
+	var card models.RateCard
+	if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	card.FromLocation = strings.TrimSpace(card.FromLocation)
+	card.ToLocation = strings.TrimSpace(card.ToLocation)
+	if h.Stations != nil {
+		card.FromLocation = h.Stations.Canonical(card.FromLocation)
+		card.ToLocation = h.Stations.Canonical(card.ToLocation)
+	}
+	if err := card.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.Repo.SaveRateCard(&card); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to save rate card: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ApiResponse{
+		Success: true,
+		Message: "Rate card saved successfully",
+		Data:    card,
+	})
+}
+
+// DeleteRateCard handler
+func (h *RateCardHandler) DeleteRateCard(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid rate card ID",
+		})
+		return
+	}
+
+	if err := h.Repo.DeleteRateCard(id); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to delete rate card: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Rate card deleted successfully",
+	})
+}
+
+// QuoteBilty handler takes a draft bilty and returns the contracted rate and
+// freight of each goods line, flagging lines charged below it
+func (h *RateCardHandler) QuoteBilty(w http.ResponseWriter, r *http.Request) {
+	var bilty models.Bilty
+	if err := json.NewDecoder(r.Body).Decode(&bilty); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if bilty.Date.IsZero() {
+		bilty.Date = time.Now()
+	}
+	if h.Stations != nil {
+		bilty.FromLocation = h.Stations.Canonical(bilty.FromLocation)
+		bilty.ToLocation = h.Stations.Canonical(bilty.ToLocation)
+	}
+
+	cards, err := h.Repo.RateCardsFor(models.RateCardParties(&bilty), bilty.Date)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch rate cards: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Rate quote computed successfully",
+		Data:    models.QuoteBilty(&bilty, cards),
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/hariomtransport/backend/export"
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+)
+
+type ReportHandler struct {
+	Repo repository.ReportRepository
+}
+
+// BookingRegister handler totals bookings over the bilty query parameters,
+// grouped by ?group_by=day (default), branch, route or party. format=csv or
+// xlsx downloads it as a spreadsheet.
+func (h *ReportHandler) BookingRegister(w http.ResponseWriter, r *http.Request) {
+	q, err := parseBiltyQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = models.GroupByDay
+	}
+	if !models.ValidBookingGroup(groupBy) {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid group_by: must be day, branch, route or party",
+		})
+		return
+	}
+
+	req, err := parseExport(r, "")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rows, err := h.Repo.BookingRegister(q, groupBy)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to build booking register: " + err.Error(),
+		})
+		return
+	}
+
+	reg := models.NewBookingRegister(groupBy, q, rows)
+	if req != nil {
+		writeExport(w, req, "booking_register", export.BookingFields(groupBy), func(wb export.Workbook, fields []export.Field[models.BookingRow]) error {
+			return export.WriteBookingRegister(wb, reg, fields)
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Booking register fetched successfully",
+		Data:    reg,
+	})
+}
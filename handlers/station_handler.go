@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hariomtransport/backend/stations"
+)
+
+type StationHandler struct {
+	Stations *stations.Directory
+}
+
+// Autocomplete result sizes
+const (
+	defaultStationLimit = 20
+	maxStationLimit     = 100
+)
+
+// SearchStations handler backs the location autocomplete: ?q= matches station
+// names and aliases, or a six-digit pincode. ?pincode= returns the single
+// station covering that pincode.
+func (h *StationHandler) SearchStations(w http.ResponseWriter, r *http.Request) {
+	if pin := r.URL.Query().Get("pincode"); pin != "" {
+		station := h.Stations.ByPincode(pin)
+		if station == nil {
+			writeJSON(w, http.StatusNotFound, ApiResponse{
+				Success: false,
+				Message: "No station covers pincode " + pin,
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, ApiResponse{
+			Success: true,
+			Message: "Station fetched successfully",
+			Data:    station,
+		})
+		return
+	}
+
+	limit := defaultStationLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, ApiResponse{
+				Success: false,
+				Message: "Invalid limit",
+			})
+			return
+		}
+		limit = min(n, maxStationLimit)
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Stations fetched successfully",
+		Data:    h.Stations.Search(r.URL.Query().Get("q"), limit),
+	})
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/tally"
+	"github.com/hariomtransport/backend/utils"
+)
+
+type TallyHandler struct {
+	Repo    repository.TallyRepository
+	Initial repository.InitialRepository
+	Ledgers tally.Ledgers
+	Audit   *utils.Auditor
+}
+
+// ExportVouchers handler downloads Tally XML vouchers for the complete
+// bilties in ?date_from=&date_to= not exported before, and records them as
+// an export batch that /tally/exports/{id} downloads again. preview=true
+// returns the same file without marking anything.
+func (h *TallyHandler) ExportVouchers(w http.ResponseWriter, r *http.Request) {
+	q, err := parseBiltyQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if q.DateFrom == nil || q.DateTo == nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Provide date_from and date_to, or date",
+		})
+		return
+	}
+	preview := r.URL.Query().Get("preview") == "true"
+
+	vouchers, err := h.voucherBuilder()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to prepare vouchers: " + err.Error(),
+		})
+		return
+	}
+
+	var batch *models.TallyExport
+	var bilties []*models.Bilty
+	if preview {
+		bilties, err = h.Repo.PendingBilties(q)
+	} else {
+		batch, bilties, err = h.Repo.CreateExport(q, time.Now().UTC())
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch bilty records: " + err.Error(),
+		})
+		return
+	}
+	if len(bilties) == 0 {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "No unexported bilty found for the given dates",
+		})
+		return
+	}
+
+	list := vouchers(bilties)
+	name := fmt.Sprintf("tally_%s_%s_preview", q.DateFrom.Format("20060102"), q.DateTo.Format("20060102"))
+	if batch != nil {
+		ids := make([]int64, len(bilties))
+		for i, b := range bilties {
+			ids[i] = b.ID
+		}
+		h.Audit.Record(r.Context(), &models.AuditEvent{
+			Action: models.AuditTallyExport,
+			Entity: models.AuditEntityBilty,
+			Payload: map[string]interface{}{
+				"export_id": batch.ID,
+				"date_from": q.DateFrom.Format("2006-01-02"),
+				"date_to":   q.DateTo.Format("2006-01-02"),
+				"bilty_ids": ids,
+				"vouchers":  len(list),
+			},
+		})
+		name = exportFileName(batch)
+	}
+	h.writeVouchers(w, name, list)
+}
+
+// ListExports handler lists the Tally export batches, newest first
+func (h *TallyHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	list, err := h.Repo.ListExports()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch exports: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Exports fetched successfully",
+		Data:    list,
+	})
+}
+
+// DownloadExport handler downloads the vouchers of an earlier export batch
+// again, built from the bilties as they are now. The vouchers keep their
+// remote IDs, so Tally updates the ones it already imported.
+func (h *TallyHandler) DownloadExport(w http.ResponseWriter, r *http.Request, idStr string) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid export ID",
+		})
+		return
+	}
+
+	vouchers, err := h.voucherBuilder()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to prepare vouchers: " + err.Error(),
+		})
+		return
+	}
+
+	batch, bilties, err := h.Repo.GetExport(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch export: " + err.Error(),
+		})
+		return
+	}
+	if batch == nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Export not found",
+		})
+		return
+	}
+
+	h.writeVouchers(w, exportFileName(batch), vouchers(bilties))
+}
+
+// voucherBuilder reads what vouchers depend on besides the bilties: the
+// transporter's GST state, which decides between CGST/SGST and IGST, and the
+// party ledger names
+func (h *TallyHandler) voucherBuilder() (func([]*models.Bilty) []tally.Voucher, error) {
+	homeState := ""
+	setup, err := h.Initial.GetInitial()
+	if err != nil {
+		return nil, fmt.Errorf("initial setup: %w", err)
+	}
+	if setup != nil && len(setup.GSTIN) >= 2 {
+		homeState = setup.GSTIN[:2]
+	}
+
+	ledgers, err := h.Repo.ListLedgers()
+	if err != nil {
+		return nil, fmt.Errorf("ledgers: %w", err)
+	}
+	names := map[int64]string{}
+	for _, l := range ledgers {
+		names[l.CompanyID] = l.LedgerName
+	}
+	partyLedger := func(c *models.Company) string {
+		if name, ok := names[c.ID]; ok {
+			return name
+		}
+		return strings.TrimSpace(c.Name)
+	}
+
+	return func(bilties []*models.Bilty) []tally.Voucher {
+		return tally.Vouchers(bilties, h.Ledgers, homeState, partyLedger)
+	}, nil
+}
+
+func exportFileName(batch *models.TallyExport) string {
+	return fmt.Sprintf("tally_%d_%s_%s", batch.ID, batch.DateFrom.Format("20060102"), batch.DateTo.Format("20060102"))
+}
+
+func (h *TallyHandler) writeVouchers(w http.ResponseWriter, name string, vouchers []tally.Voucher) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xml"`, name))
+	w.Header().Set("Cache-Control", "private, no-store")
+	if err := tally.Write(w, h.Ledgers, vouchers); err != nil {
+		log.Printf("⚠️ Tally export %s aborted: %v", name, err)
+	}
+}
+
+// ListLedgers handler lists the parties mapped to Tally ledger names
+func (h *TallyHandler) ListLedgers(w http.ResponseWriter, r *http.Request) {
+	list, err := h.Repo.ListLedgers()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch ledgers: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Ledgers fetched successfully",
+		Data:    list,
+	})
+}
+
+// SaveLedger handler maps a party to a Tally ledger name
+func (h *TallyHandler) SaveLedger(w http.ResponseWriter, r *http.Request) {
+	var l models.TallyLedger
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	l.LedgerName = strings.TrimSpace(l.LedgerName)
+	if l.CompanyID == 0 || l.LedgerName == "" {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "company_id and ledger_name are required",
+		})
+		return
+	}
+
+	if err := h.Repo.SaveLedger(&l); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to save ledger: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Ledger saved successfully",
+		Data:    l,
+	})
+}
+
+// DeleteLedger handler removes a party's ledger name, given as ?company_id=
+func (h *TallyHandler) DeleteLedger(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.ParseInt(r.URL.Query().Get("company_id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid company ID",
+		})
+		return
+	}
+
+	if err := h.Repo.DeleteLedger(companyID); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to delete ledger: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Ledger deleted successfully",
+	})
+}
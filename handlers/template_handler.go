@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/utils"
+)
+
+type TemplateHandler struct {
+	Repo    repository.TemplateRepository
+	Store   *utils.TemplateStore
+	PDFRepo *repository.PDFRepository
+}
+
+// templateName reads the template name from the query, defaulting to the bilty template
+func templateName(r *http.Request) string {
+	if name := r.URL.Query().Get("name"); name != "" {
+		return name
+	}
+	return utils.DefaultTemplateName
+}
+
+// ListTemplates handler
+func (h *TemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	list, err := h.Repo.GetTemplates(templateName(r))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch templates: " + err.Error(),
+		})
+		return
+	}
+
+	if list == nil {
+		list = []*models.BiltyTemplate{}
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Templates fetched successfully",
+		Data:    list,
+	})
+}
+
+// UploadTemplate handler stores a new template version, optionally activating it
+func (h *TemplateHandler) UploadTemplate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name"`
+		Content  string `json:"content"`
+		Activate bool   `json:"activate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = utils.DefaultTemplateName
+	}
+	if req.Content == "" {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Template content is required",
+		})
+		return
+	}
+	if err := h.Store.Validate(req.Name, req.Content); err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid template: " + err.Error(),
+		})
+		return
+	}
+
+	t := &models.BiltyTemplate{Name: req.Name, Content: req.Content}
+	if user := CurrentUser(r); user != nil {
+		t.CreatedBy = &user.UserID
+	}
+
+	if err := h.Repo.CreateTemplate(t); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to save template: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Activate {
+		if _, err := h.Store.Activate(t.ID); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ApiResponse{
+				Success: false,
+				Message: "Template saved but activation failed: " + err.Error(),
+			})
+			return
+		}
+		t.IsActive = true
+	}
+
+	writeJSON(w, http.StatusCreated, ApiResponse{
+		Success: true,
+		Message: "Template uploaded successfully",
+		Data:    t,
+	})
+}
+
+// ActivateTemplate handler
+func (h *TemplateHandler) ActivateTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ApiResponse{
+			Success: false,
+			Message: "Invalid request method",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid template ID",
+		})
+		return
+	}
+
+	t, err := h.Store.Activate(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to activate template: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Template activated successfully",
+		Data:    t,
+	})
+}
+
+// RollbackTemplate handler re-activates the previous version
+func (h *TemplateHandler) RollbackTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ApiResponse{
+			Success: false,
+			Message: "Invalid request method",
+		})
+		return
+	}
+
+	t, err := h.Store.Rollback(templateName(r))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Failed to roll back template: " + err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ApiResponse{
+		Success: true,
+		Message: "Template rolled back successfully",
+		Data:    t,
+	})
+}
+
+// PreviewTemplate renders a stored template version as HTML against a
+// real bilty (bilty_id) or the built-in sample bilty
+func (h *TemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	id, err := strconv.ParseInt(q.Get("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Invalid template ID",
+		})
+		return
+	}
+
+	t, err := h.Repo.GetTemplateByID(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch template: " + err.Error(),
+		})
+		return
+	}
+	if t == nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Template not found",
+		})
+		return
+	}
+
+	tmpl, err := h.Store.Get(t)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	bilty := utils.SampleBilty()
+	if biltyIDStr := q.Get("bilty_id"); biltyIDStr != "" {
+		biltyID, err := strconv.ParseInt(biltyIDStr, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ApiResponse{
+				Success: false,
+				Message: "Invalid bilty ID",
+			})
+			return
+		}
+		bilty, err = h.PDFRepo.GetBiltyForPDF(biltyID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ApiResponse{
+				Success: false,
+				Message: "Failed to fetch bilty: " + err.Error(),
+			})
+			return
+		}
+		if bilty == nil {
+			writeJSON(w, http.StatusNotFound, ApiResponse{
+				Success: false,
+				Message: "Bilty not found",
+			})
+			return
+		}
+	}
+
+	initial, err := h.PDFRepo.GetInitialForPDF()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch initial setup: " + err.Error(),
+		})
+		return
+	}
+	if initial == nil {
+		initial = &models.InitialSetup{}
+	}
+
+	copies, err := utils.ResolveCopies(initial, utils.ParseCopyKeys(q.Get("copies")))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	html, err := utils.RenderBiltyHTML(tmpl, utils.RenderInput{
+		Initial: initial,
+		Bilty:   bilty,
+		Copies:  copies,
+		Profile: utils.ProfileForTemplate(t.Name),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "Failed to render template: " + err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, html)
+}
@@ -3,17 +3,23 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/hariomtransport/backend/models"
 	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserHandler struct {
-	Repo repository.UserRepository
+	Repo   repository.UserRepository
+	Tokens *utils.TokenManager
+	Audit  *utils.Auditor
 }
 
-// Signup handler
+// Signup handler. Anyone can sign up as staff; only a signed in admin can
+// create an account with another role. The very first account is the admin,
+// so a new install can be set up.
 func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, ApiResponse{
@@ -32,14 +38,44 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.Name == "" || user.Email == "" || user.Role == "" {
+	if user.Name == "" || user.Email == "" {
 		writeJSON(w, http.StatusBadRequest, ApiResponse{
 			Success: false,
-			Message: "Name, email, and role are required",
+			Message: "Name and email are required",
+		})
+		return
+	}
+	if user.Role == "" {
+		user.Role = models.RoleStaff
+	}
+	if !models.ValidRole(user.Role) {
+		writeJSON(w, http.StatusBadRequest, ApiResponse{
+			Success: false,
+			Message: "role must be admin, manager or staff",
 		})
 		return
 	}
 
+	if caller := CurrentUser(r); caller == nil || caller.Role != models.RoleAdmin {
+		count, err := h.Repo.CountUsers()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ApiResponse{
+				Success: false,
+				Message: "Failed to check users: " + err.Error(),
+			})
+			return
+		}
+		if count == 0 {
+			user.Role = models.RoleAdmin
+		} else if user.Role != models.RoleStaff {
+			writeJSON(w, http.StatusForbidden, ApiResponse{
+				Success: false,
+				Message: "Only an admin can assign roles",
+			})
+			return
+		}
+	}
+
 	if err := h.Repo.CreateUser(&user); err != nil {
 		writeJSON(w, http.StatusInternalServerError, ApiResponse{
 			Success: false,
@@ -50,6 +86,13 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 
 	user.Password = "" // hide password
 
+	h.Audit.Record(r.Context(), &models.AuditEvent{
+		Action:   models.AuditUserSignup,
+		Entity:   models.AuditEntityUser,
+		EntityID: strconv.FormatInt(user.ID, 10),
+		Payload:  map[string]interface{}{"email": user.Email, "role": user.Role},
+	})
+
 	writeJSON(w, http.StatusCreated, ApiResponse{
 		Success: true,
 		Message: "User signed up successfully",
@@ -81,6 +124,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.Repo.GetUserByEmail(creds.Email)
 	if err != nil || user == nil {
+		h.auditFailedLogin(r, creds.Email, "unknown email")
 		writeJSON(w, http.StatusUnauthorized, ApiResponse{
 			Success: false,
 			Message: "Invalid email or password",
@@ -89,6 +133,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(creds.Password)); err != nil {
+		h.auditFailedLogin(r, creds.Email, "wrong password")
 		writeJSON(w, http.StatusUnauthorized, ApiResponse{
 			Success: false,
 			Message: "Invalid email or password",
@@ -98,9 +143,37 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	user.Password = "" // hide password hash
 
+	token, err := h.Tokens.Issue(user)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to issue token: " + err.Error(),
+		})
+		return
+	}
+	user.Token = token
+
+	h.Audit.Record(r.Context(), &models.AuditEvent{
+		Action:    models.AuditLogin,
+		Entity:    models.AuditEntityUser,
+		EntityID:  strconv.FormatInt(user.ID, 10),
+		ActorID:   &user.ID,
+		ActorName: user.Name,
+	})
+
 	writeJSON(w, http.StatusOK, ApiResponse{
 		Success: true,
 		Message: "Login successful",
 		Data:    user,
 	})
 }
+
+// auditFailedLogin records a rejected login; the email is kept so repeated
+// attempts against one account show up
+func (h *UserHandler) auditFailedLogin(r *http.Request, email, reason string) {
+	h.Audit.Record(r.Context(), &models.AuditEvent{
+		Action:  models.AuditLoginFailed,
+		Entity:  models.AuditEntityUser,
+		Payload: map[string]interface{}{"email": email, "reason": reason},
+	})
+}
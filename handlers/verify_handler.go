@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/utils"
+)
+
+// verifyTemplateName is the page shown when a bilty QR code is scanned
+const verifyTemplateName = "bilty_verify"
+
+type VerifyHandler struct {
+	Repo   *repository.PDFRepository
+	Signer *utils.BiltySigner
+	Store  *utils.TemplateStore
+}
+
+// BiltyVerification is the public, read-only view of a bilty
+type BiltyVerification struct {
+	CompanyName    string `json:"company_name"`
+	BiltyNo        int64  `json:"bilty_no"`
+	Date           string `json:"date"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Status         string `json:"status"`
+	DeliveryStatus string `json:"delivery_status"`
+}
+
+// VerifyBilty handler serves the page linked from the QR code on printed bilties.
+// Only a correctly signed token is accepted, so other bilties can't be enumerated.
+func (h *VerifyHandler) VerifyBilty(w http.ResponseWriter, r *http.Request, token string) {
+	biltyID, err := h.Signer.Verify(token)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Bilty not found",
+		})
+		return
+	}
+
+	bilty, err := h.Repo.GetBiltyForPDF(biltyID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to fetch bilty: " + err.Error(),
+		})
+		return
+	}
+	if bilty == nil {
+		writeJSON(w, http.StatusNotFound, ApiResponse{
+			Success: false,
+			Message: "Bilty not found",
+		})
+		return
+	}
+
+	view := BiltyVerification{
+		BiltyNo:        bilty.BiltyNo,
+		Date:           bilty.Date.Format("02-Jan-2006"),
+		From:           bilty.FromLocation,
+		To:             bilty.ToLocation,
+		Status:         bilty.Status,
+		DeliveryStatus: strings.ReplaceAll(bilty.DeliveryStatus, "_", " "),
+	}
+	if initial, err := h.Repo.GetInitialForPDF(); err == nil && initial != nil {
+		view.CompanyName = initial.CompanyName
+	}
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		writeJSON(w, http.StatusOK, ApiResponse{
+			Success: true,
+			Message: "Bilty verified",
+			Data:    view,
+		})
+		return
+	}
+
+	tmpl, _, err := h.Store.Active(verifyTemplateName)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ApiResponse{
+			Success: false,
+			Message: "Failed to load verification page: " + err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, view); err != nil {
+		http.Error(w, "Failed to render verification page", http.StatusInternalServerError)
+	}
+}
@@ -9,4 +9,17 @@ type AppUser struct {
 	Role      string    `json:"role" db:"role"`
 	Password  string    `json:"password" db:"password_hash"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Token     string    `json:"token,omitempty" db:"-" bson:"-"` // set on login only
+}
+
+// User roles
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleStaff   = "staff" // the role of self-signups
+)
+
+// ValidRole reports whether r is a known user role
+func ValidRole(r string) bool {
+	return r == RoleAdmin || r == RoleManager || r == RoleStaff
 }
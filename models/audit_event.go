@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// AuditAction names what happened in an audit event
+type AuditAction string
+
+// Audited actions
+const (
+	AuditSetupSaved  AuditAction = "setup.saved"
+	AuditUserSignup  AuditAction = "user.signup"
+	AuditLogin       AuditAction = "user.login"
+	AuditLoginFailed AuditAction = "user.login_failed"
+	AuditPDFDeleted  AuditAction = "pdf.deleted"
+	AuditTallyExport AuditAction = "tally.exported"
+)
+
+// Audited entities
+const (
+	AuditEntitySetup = "initial_setup"
+	AuditEntityUser  = "app_user"
+	AuditEntityPDF   = "pdf"
+	AuditEntityBilty = "bilty"
+)
+
+// AuditEvent records a sensitive action outside the bilty history: who did
+// what to which entity, and from where
+type AuditEvent struct {
+	ID        int64                  `json:"id" db:"id" bson:"_id"`
+	Action    AuditAction            `json:"action" db:"action" bson:"action"`
+	Entity    string                 `json:"entity" db:"entity" bson:"entity"`
+	EntityID  string                 `json:"entity_id,omitempty" db:"entity_id" bson:"entity_id,omitempty"`
+	ActorID   *int64                 `json:"actor_id,omitempty" db:"actor_id" bson:"actor_id,omitempty"` // nil for anonymous requests and background work
+	ActorName string                 `json:"actor_name,omitempty" db:"actor_name" bson:"actor_name,omitempty"`
+	IP        string                 `json:"ip,omitempty" db:"ip" bson:"ip,omitempty"`
+	UserAgent string                 `json:"user_agent,omitempty" db:"user_agent" bson:"user_agent,omitempty"`
+	Payload   map[string]interface{} `json:"payload,omitempty" db:"payload" bson:"payload,omitempty"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at" bson:"created_at"`
+}
+
+// AuditQuery filters audit events, newest first. Zero-value fields are ignored.
+type AuditQuery struct {
+	Action   AuditAction `json:"action,omitempty"`
+	Entity   string      `json:"entity,omitempty"`
+	EntityID string      `json:"entity_id,omitempty"`
+	ActorID  *int64      `json:"actor_id,omitempty"`
+	From     *time.Time  `json:"from,omitempty"` // inclusive
+	To       *time.Time  `json:"to,omitempty"`   // exclusive
+	Limit    int         `json:"limit"`
+	Offset   int         `json:"offset"`
+}
+
+// AuditPage is one page of audit events with the total number matching the query
+type AuditPage struct {
+	Events []AuditEvent `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
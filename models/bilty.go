@@ -3,39 +3,85 @@ package models
 import "time"
 
 type Bilty struct {
-	ID                 int64      `json:"id" db:"id"`
-	BiltyNo            int64      `json:"bilty_no" db:"bilty_no"`
-	ConsignorCompanyID *int64     `json:"consignor_company_id,omitempty" db:"consignor_company_id"`
-	ConsigneeCompanyID *int64     `json:"consignee_company_id,omitempty" db:"consignee_company_id"`
-	ConsignorAddressID *int64     `json:"consignor_address_id,omitempty" db:"consignor_address_id"`
-	ConsigneeAddressID *int64     `json:"consignee_address_id,omitempty" db:"consignee_address_id"`
-	FromLocation       string     `json:"from_location" db:"from_location"`
-	ToLocation         string     `json:"to_location" db:"to_location"`
-	Date               time.Time  `json:"date" db:"date"`
-	ToPay              float64    `json:"to_pay" db:"to_pay"`
-	GSTIN              *string    `json:"gstin,omitempty" db:"gstin"`
-	InvNo              *string    `json:"inv_no,omitempty" db:"inv_no"`
-	PVTMarks           *string    `json:"pvt_marks,omitempty" db:"pvt_marks"`
-	PermitNo           *string    `json:"permit_no,omitempty" db:"permit_no"`
-	ValueRupees        *float64   `json:"value_rupees,omitempty" db:"value_rupees"`
-	Remarks            *string    `json:"remarks,omitempty" db:"remarks"`
-	Hamali             *float64   `json:"hamali,omitempty" db:"hamali"`
-	DDCharges          *float64   `json:"dd_charges,omitempty" db:"dd_charges"`
-	OtherCharges       *float64   `json:"other_charges,omitempty" db:"other_charges"`
-	FOV                *float64   `json:"fov,omitempty" db:"fov"`
-	Statistical        *string    `json:"statistical,omitempty" db:"statistical"`
-	CreatedBy          int64      `json:"created_by" db:"created_by"`
-	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt          *time.Time `json:"updated_at" db:"updated_at"`
-	PdfCreatedAt       *time.Time `json:"pdf_created_at" db:"pdf_created_at"`
-	PdfPath            *string    `json:"pdf_path,omitempty" db:"pdf_path"`
-	Status             string     `json:"status" db:"status"` // draft | complete
-
-	// Nested objects for responses (denormalized)
-	ConsignorCompany     *Company      `json:"consignor_company,omitempty"`
-	ConsigneeCompany     *Company      `json:"consignee_company,omitempty"`
-	ConsignorAddressSnap *BiltyAddress `json:"consignor_address_snapshot,omitempty"`
-	ConsigneeAddressSnap *BiltyAddress `json:"consignee_address_snapshot,omitempty"`
-	CreatedByUser        *AppUser      `json:"created_by_user,omitempty"`
-	Goods                []Goods       `json:"goods,omitempty"`
+	ID                 int64      `json:"id" db:"id" bson:"_id"`
+	BiltyNo            int64      `json:"bilty_no" db:"bilty_no" bson:"bilty_no"`
+	ConsignorCompanyID *int64     `json:"consignor_company_id,omitempty" db:"consignor_company_id" bson:"consignor_company_id,omitempty"`
+	ConsigneeCompanyID *int64     `json:"consignee_company_id,omitempty" db:"consignee_company_id" bson:"consignee_company_id,omitempty"`
+	ConsignorAddressID *int64     `json:"consignor_address_id,omitempty" db:"consignor_address_id" bson:"consignor_address_id,omitempty"`
+	ConsigneeAddressID *int64     `json:"consignee_address_id,omitempty" db:"consignee_address_id" bson:"consignee_address_id,omitempty"`
+	FromLocation       string     `json:"from_location" db:"from_location" bson:"from_location"`
+	ToLocation         string     `json:"to_location" db:"to_location" bson:"to_location"`
+	Date               time.Time  `json:"date" db:"date" bson:"date"`
+	ToPay              Money      `json:"to_pay" db:"to_pay" bson:"to_pay"`
+	GSTIN              *string    `json:"gstin,omitempty" db:"gstin" bson:"gstin,omitempty"`
+	InvNo              *string    `json:"inv_no,omitempty" db:"inv_no" bson:"inv_no,omitempty"`
+	PVTMarks           *string    `json:"pvt_marks,omitempty" db:"pvt_marks" bson:"pvt_marks,omitempty"`
+	PermitNo           *string    `json:"permit_no,omitempty" db:"permit_no" bson:"permit_no,omitempty"`
+	ValueRupees        *Money     `json:"value_rupees,omitempty" db:"value_rupees" bson:"value_rupees,omitempty"`
+	Remarks            *string    `json:"remarks,omitempty" db:"remarks" bson:"remarks,omitempty"`
+	Hamali             *Money     `json:"hamali,omitempty" db:"hamali" bson:"hamali,omitempty"`
+	DDCharges          *Money     `json:"dd_charges,omitempty" db:"dd_charges" bson:"dd_charges,omitempty"`
+	OtherCharges       *Money     `json:"other_charges,omitempty" db:"other_charges" bson:"other_charges,omitempty"`
+	FOV                *Money     `json:"fov,omitempty" db:"fov" bson:"fov,omitempty"`
+	Statistical        *string    `json:"statistical,omitempty" db:"statistical" bson:"statistical,omitempty"`
+	CreatedBy          int64      `json:"created_by" db:"created_by" bson:"created_by"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at" bson:"created_at"`
+	UpdatedAt          *time.Time `json:"updated_at" db:"updated_at" bson:"updated_at,omitempty"`
+	UpdatedBy          *int64     `json:"updated_by,omitempty" db:"updated_by" bson:"updated_by,omitempty"` // user behind the last edit
+	PdfCreatedAt       *time.Time `json:"pdf_created_at" db:"pdf_created_at" bson:"pdf_created_at,omitempty"`
+	PdfPath            *string    `json:"pdf_path,omitempty" db:"pdf_path" bson:"pdf_path,omitempty"`
+	PdfHash            *string    `json:"pdf_hash,omitempty" db:"pdf_hash" bson:"pdf_hash,omitempty"`  // content hash the stored PDF was rendered from
+	Status             string     `json:"status" db:"status" bson:"status"`                            // draft | complete | cancelled
+	DeliveryStatus     string     `json:"delivery_status" db:"delivery_status" bson:"delivery_status"` // booked | in_transit | delivered
+	ReprintCount       int        `json:"reprint_count" db:"reprint_count" bson:"reprint_count"`       // duplicates printed after issue
+	BelowContract      bool       `json:"below_contract" db:"below_contract" bson:"below_contract"`    // a goods line is charged under its rate card
+
+	// GST state the freight is taxed in, set from the station master on save
+	PlaceOfSupply     *string `json:"place_of_supply,omitempty" db:"place_of_supply" bson:"place_of_supply,omitempty"`
+	PlaceOfSupplyCode *string `json:"place_of_supply_code,omitempty" db:"place_of_supply_code" bson:"place_of_supply_code,omitempty"`
+
+	// Who pays the freight held in ToPay, and the GST charged on it
+	PaymentType string `json:"payment_type" db:"payment_type" bson:"payment_type"` // to_pay | paid | tbb
+	GSTAmount   *Money `json:"gst_amount,omitempty" db:"gst_amount" bson:"gst_amount,omitempty"`
+
+	// Set when the bilty's vouchers went out in a Tally export, which then skips it
+	TallyExportedAt *time.Time `json:"tally_exported_at,omitempty" db:"tally_exported_at" bson:"tally_exported_at,omitempty"`
+	TallyExportID   *int64     `json:"tally_export_id,omitempty" db:"tally_export_id" bson:"tally_export_id,omitempty"`
+
+	// Nested objects for responses (denormalized), stored in their own collections
+	ConsignorCompany     *Company      `json:"consignor_company,omitempty" bson:"-"`
+	ConsigneeCompany     *Company      `json:"consignee_company,omitempty" bson:"-"`
+	ConsignorAddressSnap *BiltyAddress `json:"consignor_address_snapshot,omitempty" bson:"-"`
+	ConsigneeAddressSnap *BiltyAddress `json:"consignee_address_snapshot,omitempty" bson:"-"`
+	CreatedByUser        *AppUser      `json:"created_by_user,omitempty" bson:"-"`
+	Goods                []Goods       `json:"goods,omitempty" bson:"-"`
+}
+
+// Freight payment types
+const (
+	PaymentToPay = "to_pay" // the consignee pays on delivery
+	PaymentPaid  = "paid"   // the consignor paid at booking
+	PaymentTBB   = "tbb"    // to be billed to the consignor
+)
+
+// ValidPaymentType reports whether t is a known payment type
+func ValidPaymentType(t string) bool {
+	return t == PaymentToPay || t == PaymentPaid || t == PaymentTBB
+}
+
+// ConsigneePays reports whether the consignee is the party paying the freight;
+// bilties saved before payment types existed are to-pay
+func (b *Bilty) ConsigneePays() bool {
+	return b.PaymentType == "" || b.PaymentType == PaymentToPay
+}
+
+// Charges adds up the hamali, door delivery, FOV and other charges
+func (b *Bilty) Charges() Money {
+	return SumMoney(b.Hamali, b.DDCharges, b.FOV, b.OtherCharges)
+}
+
+// Freight is the freight alone: ToPay is the total printed on the bilty and
+// already includes its charges. GST is charged over ToPay.
+func (b *Bilty) Freight() Money {
+	return b.ToPay - b.Charges()
 }
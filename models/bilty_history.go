@@ -0,0 +1,137 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Bilty history actions
+const (
+	HistoryCreate = "create"
+	HistoryUpdate = "update"
+	HistoryStatus = "status" // only the status changed
+	HistoryCancel = "cancel"
+	HistoryPDF    = "pdf"
+)
+
+// BiltyHistory is one entry of a bilty's append-only audit trail
+type BiltyHistory struct {
+	ID        int64         `json:"id" db:"id" bson:"_id"`
+	BiltyID   int64         `json:"bilty_id" db:"bilty_id" bson:"bilty_id"`
+	Action    string        `json:"action" db:"action" bson:"action"`
+	UserID    *int64        `json:"user_id,omitempty" db:"user_id" bson:"user_id,omitempty"` // nil when nobody was signed in
+	Changes   []FieldChange `json:"changes,omitempty" db:"changes" bson:"changes,omitempty"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at" bson:"created_at"`
+}
+
+// FieldChange is the before and after value of one bilty field, keyed by its JSON name
+type FieldChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
+
+// historyIgnored are bilty fields that change without anyone editing the bilty:
+// keys, bookkeeping and the IDs behind the nested parties and addresses
+var historyIgnored = map[string]bool{
+	"id": true, "bilty_no": true, "created_by": true, "created_by_user": true,
+	"created_at": true, "updated_at": true, "updated_by": true, "tally_exported_at": true, "tally_export_id": true,
+	"pdf_created_at": true, "pdf_path": true, "pdf_hash": true, "reprint_count": true, "below_contract": true,
+	"consignor_company_id": true, "consignee_company_id": true,
+	"consignor_address_id": true, "consignee_address_id": true,
+}
+
+// nestedIgnored are keys of nested objects (companies, addresses, goods) that
+// are storage details rather than content
+var nestedIgnored = map[string]bool{
+	"id": true, "bilty_id": true, "company_id": true, "created_at": true,
+}
+
+// DiffBilty lists the fields that differ between two versions of a bilty,
+// sorted by field name. A nil before diffs against an empty bilty.
+func DiffBilty(before, after *Bilty) []FieldChange {
+	return DiffJSON(before, after, historyIgnored)
+}
+
+// DiffJSON lists the JSON fields that differ between two versions of a
+// record, sorted by field name and skipping the ignored ones. Nested objects
+// are compared without their storage keys.
+func DiffJSON(before, after interface{}, ignored map[string]bool) []FieldChange {
+	old, cur := jsonFields(before, ignored), jsonFields(after, ignored)
+
+	fields := make([]string, 0, len(cur))
+	for f := range old {
+		fields = append(fields, f)
+	}
+	for f := range cur {
+		if _, ok := old[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if !reflect.DeepEqual(old[f], cur[f]) {
+			changes = append(changes, FieldChange{Field: f, Old: old[f], New: cur[f]})
+		}
+	}
+	return changes
+}
+
+// HistoryAction names an update by what changed: a cancellation, a status
+// change on its own, or a general update
+func HistoryAction(changes []FieldChange) string {
+	for _, c := range changes {
+		if c.Field == "status" && c.New == "cancelled" {
+			return HistoryCancel
+		}
+	}
+	if len(changes) == 1 && changes[0].Field == "status" {
+		return HistoryStatus
+	}
+	return HistoryUpdate
+}
+
+// jsonFields flattens a record to its JSON fields, dropping ignored and empty ones
+func jsonFields(v interface{}, ignored map[string]bool) map[string]interface{} {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep amounts as written, e.g. 2450.50
+	if err := dec.Decode(&fields); err != nil || fields == nil {
+		return map[string]interface{}{} // a nil record decodes to a nil map
+	}
+	for f, v := range fields {
+		if ignored[f] || v == nil {
+			delete(fields, f)
+			continue
+		}
+		fields[f] = stripNested(v)
+	}
+	return fields
+}
+
+func stripNested(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k := range t {
+			if nestedIgnored[k] {
+				delete(t, k)
+			} else {
+				t[k] = stripNested(t[k])
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = stripNested(t[i])
+		}
+	}
+	return v
+}
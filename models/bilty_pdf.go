@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// BiltyPDF records the stored PDF of one print variant of a bilty
+type BiltyPDF struct {
+	BiltyID   int64     `json:"bilty_id" bson:"bilty_id" db:"bilty_id"`
+	Variant   string    `json:"variant" bson:"variant" db:"variant"` // e.g. "consignor+driver"
+	PdfPath   string    `json:"pdf_path" bson:"pdf_path" db:"pdf_path"`
+	Hash      string    `json:"hash" bson:"hash" db:"pdf_hash"` // content hash the PDF was rendered from
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// PDFOptions selects how a bilty is printed
+type PDFOptions struct {
+	Copies  []string `json:"copies,omitempty" bson:"copies,omitempty"`   // copy keys, empty for the default set
+	Reprint bool     `json:"reprint,omitempty" bson:"reprint,omitempty"` // print an issued bilty again as a duplicate
+	Profile string   `json:"profile,omitempty" bson:"profile,omitempty"` // print profile, empty for the default (A4)
+	// Language is en, hi or bilingual; empty uses the company's language
+	Language string `json:"language,omitempty" bson:"language,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// BiltyQuery selects bilties by ID list or by date range and party/route filters.
+// Zero-value fields are ignored.
+type BiltyQuery struct {
+	IDs                []int64    `json:"ids,omitempty"`
+	DateFrom           *time.Time `json:"date_from,omitempty"` // inclusive
+	DateTo             *time.Time `json:"date_to,omitempty"`   // inclusive
+	Status             string     `json:"status,omitempty"`
+	FromLocation       string     `json:"from_location,omitempty"`
+	ToLocation         string     `json:"to_location,omitempty"`
+	ConsignorCompanyID *int64     `json:"consignor_company_id,omitempty"`
+	ConsigneeCompanyID *int64     `json:"consignee_company_id,omitempty"`
+}
+
+// IsEmpty reports whether no filter is set, which would match every bilty
+func (q *BiltyQuery) IsEmpty() bool {
+	return len(q.IDs) == 0 && q.DateFrom == nil && q.DateTo == nil && q.Status == "" &&
+		q.FromLocation == "" && q.ToLocation == "" &&
+		q.ConsignorCompanyID == nil && q.ConsigneeCompanyID == nil
+}
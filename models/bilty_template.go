@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// BiltyTemplate is an uploaded version of a named print template.
+// Only one version per name is active at a time.
+type BiltyTemplate struct {
+	ID          int64      `json:"id" bson:"_id,omitempty" db:"id"`
+	Name        string     `json:"name" bson:"name" db:"name"`
+	Version     int        `json:"version" bson:"version" db:"version"`
+	Content     string     `json:"content,omitempty" bson:"content" db:"content"`
+	IsActive    bool       `json:"is_active" bson:"is_active" db:"is_active"`
+	CreatedBy   *int64     `json:"created_by,omitempty" bson:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty" bson:"activated_at,omitempty" db:"activated_at"`
+}
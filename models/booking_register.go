@@ -0,0 +1,87 @@
+package models
+
+// Booking register groupings
+const (
+	GroupByDay    = "day"
+	GroupByBranch = "branch" // the booking station, from_location
+	GroupByRoute  = "route"
+	GroupByParty  = "party" // the party paying the freight
+)
+
+// ValidBookingGroup reports whether g is a known booking register grouping
+func ValidBookingGroup(g string) bool {
+	return g == GroupByDay || g == GroupByBranch || g == GroupByRoute || g == GroupByParty
+}
+
+// BookingTotals adds up a set of bilties. ToPay, Paid and TBB split the
+// bilty totals, which include the charges, by payment type.
+type BookingTotals struct {
+	Bilties      int64   `json:"bilties"`
+	Packages     int64   `json:"packages"`
+	WeightKG     float64 `json:"weight_kg"`
+	ToPay        Money   `json:"to_pay"`
+	Paid         Money   `json:"paid"`
+	TBB          Money   `json:"tbb"`
+	Freight      Money   `json:"freight"`
+	Hamali       Money   `json:"hamali"`
+	DDCharges    Money   `json:"dd_charges"`
+	FOV          Money   `json:"fov"`
+	OtherCharges Money   `json:"other_charges"`
+	GST          Money   `json:"gst"`
+	Total        Money   `json:"total"` // as printed on the bilties: freight and charges, GST extra
+}
+
+// Complete fills the total, and the freight net of charges, from the other
+// amounts
+func (t *BookingTotals) Complete() {
+	t.Total = t.ToPay + t.Paid + t.TBB
+	t.Freight = t.Total - t.Hamali - t.DDCharges - t.FOV - t.OtherCharges
+}
+
+// Add accumulates o into t
+func (t *BookingTotals) Add(o BookingTotals) {
+	t.Bilties += o.Bilties
+	t.Packages += o.Packages
+	t.WeightKG += o.WeightKG
+	t.ToPay += o.ToPay
+	t.Paid += o.Paid
+	t.TBB += o.TBB
+	t.Hamali += o.Hamali
+	t.DDCharges += o.DDCharges
+	t.FOV += o.FOV
+	t.OtherCharges += o.OtherCharges
+	t.GST += o.GST
+	t.Complete()
+}
+
+// BookingRow is one group of the register; only the fields of its grouping
+// are set
+type BookingRow struct {
+	Day          string `json:"day,omitempty"` // YYYY-MM-DD
+	FromLocation string `json:"from_location,omitempty"`
+	ToLocation   string `json:"to_location,omitempty"`
+	PartyID      *int64 `json:"party_id,omitempty"`
+	PartyName    string `json:"party_name,omitempty"`
+	BookingTotals
+}
+
+// BookingRegister is the booking register for a date range
+type BookingRegister struct {
+	GroupBy string        `json:"group_by"`
+	Query   *BiltyQuery   `json:"query"`
+	Rows    []BookingRow  `json:"rows"`
+	Totals  BookingTotals `json:"totals"`
+}
+
+// NewBookingRegister totals the rows of a register
+func NewBookingRegister(groupBy string, q *BiltyQuery, rows []BookingRow) *BookingRegister {
+	reg := &BookingRegister{GroupBy: groupBy, Query: q, Rows: rows}
+	if reg.Rows == nil {
+		reg.Rows = []BookingRow{}
+	}
+	for _, row := range reg.Rows {
+		reg.Totals.Add(row.BookingTotals)
+	}
+	reg.Totals.Complete()
+	return reg
+}
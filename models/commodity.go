@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Commodity is an entry of the goods catalogue. Goods lines refer to it so the
+// same item is counted together however its particulars were typed.
+type Commodity struct {
+	ID          int64      `json:"id" db:"id" bson:"_id"`
+	Name        string     `json:"name" db:"name" bson:"name"`
+	HSNCode     *string    `json:"hsn_code,omitempty" db:"hsn_code" bson:"hsn_code,omitempty"` // HSN or SAC code
+	Per         *string    `json:"per,omitempty" db:"per" bson:"per,omitempty"`                // default unit the rate is charged per
+	DefaultRate *Money     `json:"default_rate,omitempty" db:"default_rate" bson:"default_rate,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at" bson:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" db:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// CommodityTotal is what was booked of one commodity; lines without a
+// commodity are grouped under a nil CommodityID
+type CommodityTotal struct {
+	CommodityID *int64  `json:"commodity_id"`
+	Name        string  `json:"name"`
+	HSNCode     *string `json:"hsn_code,omitempty"`
+	Bilties     int64   `json:"bilties"`
+	Packages    int64   `json:"packages"`
+	WeightKG    float64 `json:"weight_kg"`
+	Tonnes      float64 `json:"tonnes"`
+	Freight     Money   `json:"freight"`
+}
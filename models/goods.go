@@ -1,12 +1,14 @@
 package models
 
 type Goods struct {
-	ID          int64    `json:"id" db:"id"`
-	BiltyID     int64    `json:"bilty_id" db:"bilty_id"`
-	Particulars string   `json:"particulars" db:"particulars"`
-	NumOfPkts   int      `json:"num_of_pkts" db:"num_of_pkts"`
-	WeightKG    *float64 `json:"weight_kg,omitempty" db:"weight_kg"`
-	Rate        *float64 `json:"rate,omitempty" db:"rate"`
-	Per         *string  `json:"per,omitempty" db:"per"`
-	Amount      *float64 `json:"amount,omitempty" db:"amount"`
+	ID          int64    `json:"id" db:"id" bson:"id"`
+	BiltyID     int64    `json:"bilty_id" db:"bilty_id" bson:"bilty_id"`
+	Seq         int      `json:"seq" db:"seq" bson:"seq"`                                                // line position on the bilty, from 1
+	CommodityID *int64   `json:"commodity_id,omitempty" db:"commodity_id" bson:"commodity_id,omitempty"` // catalogue entry; Particulars is what prints
+	Particulars string   `json:"particulars" db:"particulars" bson:"particulars"`
+	NumOfPkts   int      `json:"num_of_pkts" db:"num_of_pkts" bson:"num_of_pkts"`
+	WeightKG    *float64 `json:"weight_kg,omitempty" db:"weight_kg" bson:"weight_kg,omitempty"`
+	Rate        *Money   `json:"rate,omitempty" db:"rate" bson:"rate,omitempty"`
+	Per         *string  `json:"per,omitempty" db:"per" bson:"per,omitempty"`
+	Amount      *Money   `json:"amount,omitempty" db:"amount" bson:"amount,omitempty"`
 }
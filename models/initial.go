@@ -7,6 +7,15 @@ type MobileEntry struct {
 	Label  string `json:"label" bson:"label" db:"label"`
 }
 
+// CopyConfig describes one printed copy of a bilty
+type CopyConfig struct {
+	Key      string   `json:"key" bson:"key" db:"key"` // short id used to pick copies, e.g. "consignor"
+	Title    string   `json:"title" bson:"title" db:"title"`
+	Footnote []string `json:"footnote,omitempty" bson:"footnote,omitempty" db:"footnote"` // printed below the company footnote
+	Color    string   `json:"color,omitempty" bson:"color,omitempty" db:"color"`          // CSS colour of the copy title
+	Default  bool     `json:"default" bson:"default" db:"default"`                        // printed when no copies are requested
+}
+
 type InitialSetup struct {
 	ID          int64         `json:"id" bson:"_id,omitempty" db:"id"`
 	CompanyName string        `json:"company_name" bson:"name" db:"name"`
@@ -17,5 +26,8 @@ type InitialSetup struct {
 	GSTIN       string        `json:"gstin" bson:"gstin" db:"gstin"`
 	Footnote    []string      `json:"footnote" bson:"footnote" db:"footnote"`
 	Mobile      []MobileEntry `json:"mobile" bson:"mobile" db:"mobile"`
+	Copies      []CopyConfig  `json:"copies" bson:"copies" db:"copies"`
+	TextLayout  *TextLayout   `json:"text_layout,omitempty" bson:"text_layout,omitempty" db:"text_layout"` // dot-matrix field positions
+	Language    string        `json:"language,omitempty" bson:"language,omitempty" db:"language"`          // print language: en (default), hi or bilingual
 	CreatedAt   time.Time     `json:"created_at" bson:"created_at" db:"created_at"`
 }
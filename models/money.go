@@ -0,0 +1,200 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/hariomtransport/backend/amountwords"
+)
+
+// Money is an amount in whole paise. It matches Postgres NUMERIC(12,2)
+// exactly, so sums don't drift the way float64 rupees do. JSON carries it as
+// a rupee number with two decimals (2450.50), Postgres as NUMERIC and Mongo
+// as Decimal128.
+type Money int64
+
+// MoneyFromRupees converts a float rupee amount, rounding to the nearest paisa
+// as the amount is written (0.995 becomes 1.00)
+func MoneyFromRupees(rupees float64) Money {
+	return Money(amountwords.ToPaise(rupees))
+}
+
+// ParseMoney reads a decimal rupee amount such as "2450.5" or "-12", rounding
+// half away from zero to the nearest paisa
+func ParseMoney(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r.Mul(r, big.NewRat(100, 1))
+
+	// Round half away from zero: truncate |r| + 1/2
+	neg := r.Sign() < 0
+	r.Abs(r)
+	r.Add(r, big.NewRat(1, 2))
+	paise := new(big.Int).Quo(r.Num(), r.Denom())
+	if !paise.IsInt64() {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+	if neg {
+		return Money(-paise.Int64()), nil
+	}
+	return Money(paise.Int64()), nil
+}
+
+// Paise returns the amount in paise
+func (m Money) Paise() int64 { return int64(m) }
+
+// Rupees returns the amount as float rupees, for display only
+func (m Money) Rupees() float64 { return float64(m) / 100 }
+
+// Add returns m + o
+func (m Money) Add(o Money) Money { return m + o }
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money { return m - o }
+
+// Mul returns m times a quantity such as a weight, rounded to the nearest paisa
+func (m Money) Mul(qty float64) Money {
+	return Money(math.Round(float64(m) * qty))
+}
+
+// SumMoney adds up amounts; nil ones count as zero
+func SumMoney(amounts ...*Money) Money {
+	var total Money
+	for _, a := range amounts {
+		total += MoneyOrZero(a)
+	}
+	return total
+}
+
+// MoneyOrZero dereferences an optional amount
+func MoneyOrZero(m *Money) Money {
+	if m == nil {
+		return 0
+	}
+	return *m
+}
+
+// NewMoney returns a pointer to m, for optional amount fields
+func NewMoney(m Money) *Money { return &m }
+
+// String formats the amount in rupees with two decimals, e.g. "2450.50"
+func (m Money) String() string {
+	sign := ""
+	p := uint64(m)
+	if m < 0 {
+		sign = "-"
+		p = uint64(-(m + 1)) + 1
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, p/100, p%100)
+}
+
+// MarshalJSON writes the amount as a rupee number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a rupee number or a numeric string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan reads a NUMERIC column
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case int64:
+		*m = Money(v * 100)
+	case float64:
+		*m = MoneyFromRupees(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+// Value writes the amount as a NUMERIC literal
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// MarshalBSONValue stores the amount as Decimal128
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(m.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(d)
+}
+
+// UnmarshalBSONValue reads Decimal128, and the doubles and integers older
+// documents were saved with
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*m = 0
+	case bsontype.Decimal128:
+		parsed, err := ParseMoney(raw.Decimal128().String())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case bsontype.Double:
+		*m = MoneyFromRupees(raw.Double())
+	case bsontype.Int32:
+		*m = Money(int64(raw.Int32()) * 100)
+	case bsontype.Int64:
+		*m = Money(raw.Int64() * 100)
+	case bsontype.String:
+		parsed, err := ParseMoney(raw.StringValue())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	default:
+		return fmt.Errorf("cannot decode BSON %s into Money", t)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"2450.5", 245050, false},
+		{"2450.50", 245050, false},
+		{" 12 ", 1200, false},
+		{"-12", -1200, false},
+		{"0.005", 1, false},
+		{"0.004", 0, false},
+		{"0.995", 100, false},
+		{"-0.005", -1, false},
+		{"-0.994", -99, false},
+		{"1.2345", 123, false},
+		{"1.235", 124, false},
+		{"1e3", 100000, false},
+		{"abc", 0, true},
+		{"", 0, true},
+		{"1e30", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{245050, "2450.50"},
+		{-5, "-0.05"},
+		{-245050, "-2450.50"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.m), got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{"nil", nil, 0, false},
+		{"bytes", []byte("2450.50"), 245050, false},
+		{"bytes negative", []byte("-0.75"), -75, false},
+		{"string", "99.999", 10000, false},
+		{"int64 rupees", int64(42), 4200, false},
+		{"float64", 0.995, 100, false},
+		{"float64 negative", -12.345, -1235, false},
+		{"bad bytes", []byte("12,50"), 0, true},
+		{"unsupported", true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money(-1)
+			err := m.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan(%v) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+			}
+			if !tt.wantErr && m != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{`2450.5`, 245050, false},
+		{`"2450.5"`, 245050, false},
+		{`-0.005`, -1, false},
+		{`0.125`, 13, false},
+		{`null`, 7, false}, // left as it was
+		{`"abc"`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m := Money(7)
+			err := json.Unmarshal([]byte(tt.in), &m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && m != tt.want {
+				t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, m, tt.want)
+			}
+		})
+	}
+
+	out, err := json.Marshal(struct {
+		A Money  `json:"a"`
+		B *Money `json:"b"`
+	}{A: -245050, B: NewMoney(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":-2450.50,"b":0.05}`; string(out) != want {
+		t.Errorf("Marshal = %s, want %s", out, want)
+	}
+}
+
+func TestMoneyBSON(t *testing.T) {
+	type doc struct {
+		Amount Money  `bson:"amount"`
+		Opt    *Money `bson:"opt,omitempty"`
+	}
+
+	for _, m := range []Money{0, 1, 245050, -75, 1234567890123} {
+		data, err := bson.Marshal(doc{Amount: m, Opt: NewMoney(m)})
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", m, err)
+		}
+		if typ := bson.Raw(data).Lookup("amount").Type; typ != bson.TypeDecimal128 {
+			t.Errorf("Marshal(%d) stored %s, want decimal128", m, typ)
+		}
+		var got doc
+		if err := bson.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%d): %v", m, err)
+		}
+		if got.Amount != m || got.Opt == nil || *got.Opt != m {
+			t.Errorf("round trip of %d = %d, %v", m, got.Amount, got.Opt)
+		}
+	}
+
+	dec := func(s string) primitive.Decimal128 {
+		d, err := primitive.ParseDecimal128(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name string
+		in   interface{}
+		want Money
+	}{
+		{"decimal128", dec("2450.50"), 245050},
+		{"decimal128 exponent", dec("1.5E+3"), 150000},
+		{"decimal128 half paisa", dec("0.005"), 1},
+		{"decimal128 negative", dec("-0.125"), -13},
+		{"double", 0.995, 100},
+		{"int32 rupees", int32(12), 1200},
+		{"int64 rupees", int64(-3), -300},
+		{"string", "99.5", 9950},
+		{"null", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(bson.M{"amount": tt.in})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := doc{Amount: -1}
+			if err := bson.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal(%v): %v", tt.in, err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Unmarshal(%v) = %d, want %d", tt.in, got.Amount, tt.want)
+			}
+		})
+	}
+
+	data, _ := bson.Marshal(bson.M{"amount": true})
+	if err := bson.Unmarshal(data, &doc{}); err == nil {
+		t.Error("Unmarshal(bool) succeeded, want an error")
+	}
+}
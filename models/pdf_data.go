@@ -1,12 +1,21 @@
 package models
 
+import "html/template"
+
 type BiltyPDFData struct {
-	Company    *InitialSetup // Company / Initial setup
-	Bilty      *Bilty        // Bilty details
-	Contacts   string        // formatted mobile numbers
-	Date       string        // formatted date
-	Total      float64       // total amount including charges
-	TotalWords string
-	CopyTitle  string
-	GoodsCount int
+	Company       *InitialSetup // Company / Initial setup
+	Bilty         *Bilty        // Bilty details
+	Contacts      string        // formatted mobile numbers
+	Date          string        // formatted date
+	Total         Money         // total amount including charges
+	TotalWords    string        // total in words, in the print language
+	TotalWordsAlt string        // Hindi words printed below the English ones on a bilingual print
+	CopyTitle     string
+	CopyColor     string   // CSS colour for the copy title, empty for default
+	CopyNotes     []string // extra footnote lines for this copy
+	GoodsCount    int
+	QRCode        template.URL      // data URI of the verification QR code, empty when disabled
+	Watermark     string            // DRAFT, CANCELLED or DUPLICATE, empty for an original print
+	Language      string            // en, hi or bilingual
+	Labels        map[string]string // field captions in the print language, e.g. {{.Labels.consignor}}
 }
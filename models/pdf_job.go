@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PDF job statuses
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// PDFJob is a queued request to generate and upload a bilty PDF
+type PDFJob struct {
+	ID          int64      `json:"id" bson:"_id,omitempty" db:"id"`
+	BiltyID     int64      `json:"bilty_id" bson:"bilty_id" db:"bilty_id"`
+	Options     PDFOptions `json:"options" bson:"options" db:"options"`
+	Status      string     `json:"status" bson:"status" db:"status"` // queued | running | done | failed
+	Attempts    int        `json:"attempts" bson:"attempts" db:"attempts"`
+	MaxAttempts int        `json:"max_attempts" bson:"max_attempts" db:"max_attempts"`
+	Result      *string    `json:"result,omitempty" bson:"result,omitempty" db:"result"` // PDF URL once done
+	LastError   *string    `json:"last_error,omitempty" bson:"last_error,omitempty" db:"last_error"`
+	RunAfter    time.Time  `json:"run_after" bson:"run_after" db:"run_after"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
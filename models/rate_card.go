@@ -0,0 +1,235 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Units a rate card charges per
+const (
+	UnitKG      = "kg"
+	UnitQuintal = "quintal" // 100 kg
+	UnitTonne   = "tonne"   // 1000 kg
+	UnitPackage = "pkt"
+	UnitFixed   = "fixed" // one charge per goods line
+)
+
+// RateCard is a contracted tariff. Empty party, route ends and commodity
+// match anything, so a card with none of them is the default rate.
+type RateCard struct {
+	ID           int64      `json:"id" db:"id" bson:"_id"`
+	PartyID      *int64     `json:"party_id,omitempty" db:"party_id" bson:"party_id,omitempty"` // consignor or consignee company
+	FromLocation string     `json:"from_location,omitempty" db:"from_location" bson:"from_location,omitempty"`
+	ToLocation   string     `json:"to_location,omitempty" db:"to_location" bson:"to_location,omitempty"`
+	CommodityID  *int64     `json:"commodity_id,omitempty" db:"commodity_id" bson:"commodity_id,omitempty"`
+	Per          string     `json:"per" db:"per" bson:"per"`
+	Slabs        []RateSlab `json:"slabs" db:"slabs" bson:"slabs"`
+	MinCharge    *Money     `json:"min_charge,omitempty" db:"min_charge" bson:"min_charge,omitempty"` // per goods line
+	ValidFrom    time.Time  `json:"valid_from" db:"valid_from" bson:"valid_from"`
+	ValidTo      *time.Time `json:"valid_to,omitempty" db:"valid_to" bson:"valid_to,omitempty"` // inclusive; nil is open-ended
+	CreatedAt    time.Time  `json:"created_at" db:"created_at" bson:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty" db:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// RateSlab is the rate for goods weighing up to UpToKG; the last slab may
+// leave it nil to cover everything heavier
+type RateSlab struct {
+	UpToKG *float64 `json:"up_to_kg,omitempty" bson:"up_to_kg,omitempty"`
+	Rate   Money    `json:"rate" bson:"rate"`
+}
+
+// Validate checks the unit, slabs and validity dates of the card
+func (c *RateCard) Validate() error {
+	c.Per = strings.ToLower(strings.TrimSpace(c.Per))
+	switch c.Per {
+	case UnitKG, UnitQuintal, UnitTonne, UnitPackage, UnitFixed:
+	default:
+		return fmt.Errorf("unknown unit %q, use kg, quintal, tonne, pkt or fixed", c.Per)
+	}
+	if len(c.Slabs) == 0 {
+		return fmt.Errorf("a rate card needs at least one slab")
+	}
+	prev := 0.0
+	for i, s := range c.Slabs {
+		if s.Rate < 0 {
+			return fmt.Errorf("slab %d has a negative rate", i+1)
+		}
+		if s.UpToKG == nil {
+			if i != len(c.Slabs)-1 {
+				return fmt.Errorf("only the last slab can be open-ended")
+			}
+			continue
+		}
+		if *s.UpToKG <= prev {
+			return fmt.Errorf("slab %d must end above %g kg", i+1, prev)
+		}
+		prev = *s.UpToKG
+	}
+	if c.MinCharge != nil && *c.MinCharge < 0 {
+		return fmt.Errorf("minimum charge cannot be negative")
+	}
+	if c.ValidFrom.IsZero() {
+		return fmt.Errorf("valid_from is required")
+	}
+	if c.ValidTo != nil && c.ValidTo.Before(c.ValidFrom) {
+		return fmt.Errorf("valid_to is before valid_from")
+	}
+	return nil
+}
+
+// ValidOn reports whether the card applies on the day of t; times of day are ignored
+func (c *RateCard) ValidOn(t time.Time) bool {
+	day := dateOf(t)
+	return !day.Before(dateOf(c.ValidFrom)) && (c.ValidTo == nil || !day.After(dateOf(*c.ValidTo)))
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// specificity ranks a card that matches a line: a party contract beats a
+// commodity rate, which beats a route rate, which beats the default
+func (c *RateCard) specificity() int {
+	score := 0
+	if c.PartyID != nil {
+		score += 8
+	}
+	if c.CommodityID != nil {
+		score += 4
+	}
+	if c.FromLocation != "" {
+		score += 2
+	}
+	if c.ToLocation != "" {
+		score++
+	}
+	return score
+}
+
+// matches reports whether the card covers the goods line of the bilty
+func (c *RateCard) matches(b *Bilty, g *Goods) bool {
+	if c.PartyID != nil && !sameID(c.PartyID, b.ConsignorCompanyID) && !sameID(c.PartyID, b.ConsigneeCompanyID) {
+		return false
+	}
+	if c.CommodityID != nil && !sameID(c.CommodityID, g.CommodityID) {
+		return false
+	}
+	if c.FromLocation != "" && !strings.EqualFold(c.FromLocation, strings.TrimSpace(b.FromLocation)) {
+		return false
+	}
+	if c.ToLocation != "" && !strings.EqualFold(c.ToLocation, strings.TrimSpace(b.ToLocation)) {
+		return false
+	}
+	return c.ValidOn(b.Date)
+}
+
+func sameID(a, b *int64) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// slab picks the slab for the weight; without a weight the first slab applies
+func (c *RateCard) slab(weightKG float64) RateSlab {
+	for _, s := range c.Slabs {
+		if s.UpToKG == nil || weightKG <= *s.UpToKG {
+			return s
+		}
+	}
+	return c.Slabs[len(c.Slabs)-1]
+}
+
+// quantity is how many units of the card's Per the line holds
+func (c *RateCard) quantity(g *Goods) float64 {
+	weight := 0.0
+	if g.WeightKG != nil {
+		weight = *g.WeightKG
+	}
+	switch c.Per {
+	case UnitQuintal:
+		return weight / 100
+	case UnitTonne:
+		return weight / 1000
+	case UnitPackage:
+		return float64(g.NumOfPkts)
+	case UnitFixed:
+		return 1
+	}
+	return weight
+}
+
+// LineQuote is the contracted freight for one goods line
+type LineQuote struct {
+	Seq            int    `json:"seq"`
+	Particulars    string `json:"particulars"`
+	RateCardID     *int64 `json:"rate_card_id"` // nil when no card covers the line
+	Per            string `json:"per,omitempty"`
+	Rate           *Money `json:"rate,omitempty"`
+	Freight        Money  `json:"freight"`
+	MinimumApplied bool   `json:"minimum_applied,omitempty"`
+	Charged        *Money `json:"charged,omitempty"` // the amount entered on the line
+	BelowContract  bool   `json:"below_contract,omitempty"`
+}
+
+// BiltyQuote is the contracted freight for a bilty
+type BiltyQuote struct {
+	Lines         []LineQuote `json:"lines"`
+	Freight       Money       `json:"freight"`
+	BelowContract bool        `json:"below_contract"`
+}
+
+// QuoteBilty prices every goods line with the most specific card that covers
+// it, and flags lines charged less than the contract
+func QuoteBilty(b *Bilty, cards []RateCard) BiltyQuote {
+	quote := BiltyQuote{Lines: []LineQuote{}}
+	for i := range b.Goods {
+		g := &b.Goods[i]
+		line := LineQuote{Seq: i + 1, Particulars: g.Particulars, Charged: g.Amount}
+
+		var card *RateCard
+		for j := range cards {
+			c := &cards[j]
+			if !c.matches(b, g) {
+				continue
+			}
+			if card == nil || c.specificity() > card.specificity() ||
+				(c.specificity() == card.specificity() && c.ValidFrom.After(card.ValidFrom)) {
+				card = c
+			}
+		}
+
+		if card != nil {
+			weight := 0.0
+			if g.WeightKG != nil {
+				weight = *g.WeightKG
+			}
+			rate := card.slab(weight).Rate
+			id := card.ID
+			line.RateCardID = &id
+			line.Per = card.Per
+			line.Rate = &rate
+			line.Freight = rate.Mul(card.quantity(g))
+			if card.MinCharge != nil && line.Freight < *card.MinCharge {
+				line.Freight = *card.MinCharge
+				line.MinimumApplied = true
+			}
+			line.BelowContract = g.Amount != nil && *g.Amount < line.Freight
+		}
+
+		quote.Freight += line.Freight
+		quote.BelowContract = quote.BelowContract || line.BelowContract
+		quote.Lines = append(quote.Lines, line)
+	}
+	return quote
+}
+
+// RateCardParties lists the company IDs of the bilty a party card can be keyed by
+func RateCardParties(b *Bilty) []int64 {
+	var ids []int64
+	for _, id := range []*int64{b.ConsignorCompanyID, b.ConsigneeCompanyID} {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuoteBilty(t *testing.T) {
+	id := func(n int64) *int64 { return &n }
+	kg := func(w float64) *float64 { return &w }
+	day := func(d int) time.Time { return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC) }
+	rupees := func(r int64) *Money { return NewMoney(Money(r * 100)) }
+
+	const consignor, consignee, other = 1, 2, 3
+	const rice, sugar = 10, 11
+
+	// Default rate per kg, slabbed: 5.00 up to 100 kg, 4.00 up to 1000 kg, 3.00 above
+	base := RateCard{ID: 1, Per: UnitKG, ValidFrom: day(1), Slabs: []RateSlab{
+		{UpToKG: kg(100), Rate: 500},
+		{UpToKG: kg(1000), Rate: 400},
+		{Rate: 300},
+	}}
+	route := RateCard{ID: 2, FromLocation: "Patna", ToLocation: "Delhi", Per: UnitQuintal, ValidFrom: day(1), Slabs: []RateSlab{{Rate: 20000}}}
+	commodity := RateCard{ID: 3, CommodityID: id(rice), Per: UnitTonne, ValidFrom: day(1), Slabs: []RateSlab{{Rate: 150000}}}
+	party := RateCard{ID: 4, PartyID: id(consignee), Per: UnitPackage, ValidFrom: day(1), Slabs: []RateSlab{{Rate: 5000}}}
+	otherParty := RateCard{ID: 5, PartyID: id(other), Per: UnitFixed, ValidFrom: day(1), Slabs: []RateSlab{{Rate: 100}}}
+	newerRoute := route
+	newerRoute.ID, newerRoute.ValidFrom = 6, day(10)
+	newerRoute.Slabs = []RateSlab{{Rate: 25000}}
+	expired := party
+	expired.ID, expired.ValidTo = 7, timePtr(day(5))
+	minimum := RateCard{ID: 8, Per: UnitFixed, ValidFrom: day(1), Slabs: []RateSlab{{Rate: 10000}}, MinCharge: rupees(250)}
+
+	bilty := func(date time.Time, goods ...Goods) *Bilty {
+		return &Bilty{
+			ConsignorCompanyID: id(consignor), ConsigneeCompanyID: id(consignee),
+			FromLocation: " patna ", ToLocation: "DELHI", Date: date, Goods: goods,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		bilty     *Bilty
+		cards     []RateCard
+		wantCards []int64 // chosen card per line, 0 for none
+		wantLines []Money
+		wantTotal Money
+		wantBelow bool
+	}{
+		{
+			name:      "no cards",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(50), Amount: rupees(10)}),
+			wantCards: []int64{0},
+			wantLines: []Money{0},
+		},
+		{
+			name:  "first slab",
+			bilty: bilty(day(15), Goods{WeightKG: kg(100)}),
+			cards: []RateCard{base},
+			// 100 kg is still in the first slab
+			wantCards: []int64{1},
+			wantLines: []Money{50000},
+			wantTotal: 50000,
+		},
+		{
+			name:      "middle and open slab",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(100.5)}, Goods{WeightKG: kg(2000)}),
+			cards:     []RateCard{base},
+			wantCards: []int64{1, 1},
+			wantLines: []Money{40200, 600000},
+			wantTotal: 640200,
+		},
+		{
+			name:      "no weight uses the first slab",
+			bilty:     bilty(day(15), Goods{NumOfPkts: 3}),
+			cards:     []RateCard{base},
+			wantCards: []int64{1},
+			wantLines: []Money{0},
+		},
+		{
+			name:      "route beats default and matches case-insensitively",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(250)}),
+			cards:     []RateCard{base, route},
+			wantCards: []int64{2},
+			wantLines: []Money{50000},
+			wantTotal: 50000,
+		},
+		{
+			name:      "commodity beats route",
+			bilty:     bilty(day(15), Goods{CommodityID: id(rice), WeightKG: kg(2000)}, Goods{CommodityID: id(sugar), WeightKG: kg(100)}),
+			cards:     []RateCard{route, commodity, base},
+			wantCards: []int64{3, 2},
+			wantLines: []Money{300000, 20000},
+			wantTotal: 320000,
+		},
+		{
+			name:      "party beats commodity",
+			bilty:     bilty(day(15), Goods{CommodityID: id(rice), NumOfPkts: 4, WeightKG: kg(2000)}),
+			cards:     []RateCard{commodity, party, otherParty},
+			wantCards: []int64{4},
+			wantLines: []Money{20000},
+			wantTotal: 20000,
+		},
+		{
+			name:      "newer card wins a tie",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(100)}),
+			cards:     []RateCard{newerRoute, route},
+			wantCards: []int64{6},
+			wantLines: []Money{25000},
+			wantTotal: 25000,
+		},
+		{
+			name:      "cards outside their dates are skipped",
+			bilty:     bilty(day(6), Goods{NumOfPkts: 2, WeightKG: kg(100)}),
+			cards:     []RateCard{expired, newerRoute, route},
+			wantCards: []int64{2},
+			wantLines: []Money{20000},
+			wantTotal: 20000,
+		},
+		{
+			name:      "minimum charge",
+			bilty:     bilty(day(15), Goods{}),
+			cards:     []RateCard{minimum},
+			wantCards: []int64{8},
+			wantLines: []Money{25000},
+			wantTotal: 25000,
+		},
+		{
+			name:      "charged below contract",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(100), Amount: rupees(499)}, Goods{WeightKG: kg(100), Amount: rupees(500)}),
+			cards:     []RateCard{base},
+			wantCards: []int64{1, 1},
+			wantLines: []Money{50000, 50000},
+			wantTotal: 100000,
+			wantBelow: true,
+		},
+		{
+			name:      "charged at or above contract",
+			bilty:     bilty(day(15), Goods{WeightKG: kg(100), Amount: rupees(500)}, Goods{WeightKG: kg(100)}),
+			cards:     []RateCard{base},
+			wantCards: []int64{1, 1},
+			wantLines: []Money{50000, 50000},
+			wantTotal: 100000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := QuoteBilty(tt.bilty, tt.cards)
+			if len(q.Lines) != len(tt.wantLines) {
+				t.Fatalf("got %d lines, want %d", len(q.Lines), len(tt.wantLines))
+			}
+			for i, line := range q.Lines {
+				var card int64
+				if line.RateCardID != nil {
+					card = *line.RateCardID
+				}
+				if card != tt.wantCards[i] {
+					t.Errorf("line %d priced with card %d, want %d", i+1, card, tt.wantCards[i])
+				}
+				if line.Freight != tt.wantLines[i] {
+					t.Errorf("line %d freight = %s, want %s", i+1, line.Freight, tt.wantLines[i])
+				}
+				if line.Seq != i+1 {
+					t.Errorf("line %d has seq %d", i+1, line.Seq)
+				}
+			}
+			if q.Freight != tt.wantTotal {
+				t.Errorf("freight = %s, want %s", q.Freight, tt.wantTotal)
+			}
+			if q.BelowContract != tt.wantBelow {
+				t.Errorf("below contract = %v, want %v", q.BelowContract, tt.wantBelow)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TallyLedger maps a party to the ledger it is kept under in Tally. Parties
+// without one are exported under their company name.
+type TallyLedger struct {
+	CompanyID   int64     `json:"company_id" db:"company_id" bson:"_id"`
+	CompanyName string    `json:"company_name,omitempty" db:"-" bson:"-"`
+	LedgerName  string    `json:"ledger_name" db:"ledger_name" bson:"ledger_name"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" bson:"updated_at"`
+}
+
+// TallyExport is one batch of bilties sent to Tally. Its file can be
+// downloaded again, say when the first download failed.
+type TallyExport struct {
+	ID         int64     `json:"id" db:"id" bson:"_id"`
+	DateFrom   time.Time `json:"date_from" db:"date_from" bson:"date_from"`
+	DateTo     time.Time `json:"date_to" db:"date_to" bson:"date_to"`
+	ExportedAt time.Time `json:"exported_at" db:"exported_at" bson:"exported_at"`
+	Bilties    int       `json:"bilties" db:"bilties" bson:"bilties"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// TextLayout places bilty fields on a fixed-column page, for dot-matrix
+// printers on pre-printed bilty books. Rows and columns count from 0.
+type TextLayout struct {
+	Width        int         `json:"width" bson:"width" db:"width"`                         // characters per line
+	Height       int         `json:"height" bson:"height" db:"height"`                      // lines per copy
+	Fields       []TextField `json:"fields" bson:"fields" db:"fields"`                      // single-value fields
+	GoodsRow     int         `json:"goods_row" bson:"goods_row" db:"goods_row"`             // first line of the goods table
+	GoodsLines   int         `json:"goods_lines" bson:"goods_lines" db:"goods_lines"`       // goods rows that fit on the form
+	GoodsColumns []TextField `json:"goods_columns" bson:"goods_columns" db:"goods_columns"` // Row is ignored
+}
+
+// TextField is one value printed at a fixed position
+type TextField struct {
+	Field string `json:"field" bson:"field" db:"field"` // e.g. "bilty_no", "consignor", "total"
+	Row   int    `json:"row" bson:"row" db:"row"`
+	Col   int    `json:"col" bson:"col" db:"col"`
+	Width int    `json:"width" bson:"width" db:"width"`
+	Align string `json:"align,omitempty" bson:"align,omitempty" db:"align"` // left (default), right or center
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// AuditRepository stores audit events. Events are never edited; the only
+// removal is PurgeEvents enforcing the retention period.
+type AuditRepository interface {
+	AddEvent(e *models.AuditEvent) error
+	ListEvents(q *models.AuditQuery) (*models.AuditPage, error)
+	// PurgeEvents deletes events created before the cutoff and returns how many
+	PurgeEvents(before time.Time) (int64, error)
+}
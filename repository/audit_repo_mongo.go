@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAuditRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoAuditRepo(db *mongo.Client) *MongoAuditRepo {
+	return &MongoAuditRepo{DB: db}
+}
+
+func (r *MongoAuditRepo) AddEvent(e *models.AuditEvent) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	id, err := nextSequence(ctx, db, "audit_event")
+	if err != nil {
+		return err
+	}
+	e.ID = id
+
+	_, err = db.Collection("audit_event").InsertOne(ctx, e)
+	return err
+}
+
+func (r *MongoAuditRepo) ListEvents(q *models.AuditQuery) (*models.AuditPage, error) {
+	ctx := context.Background()
+	coll := r.DB.Database("hariomtransport").Collection("audit_event",
+		options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+
+	filter := bson.M{}
+	if q.Action != "" {
+		filter["action"] = q.Action
+	}
+	if q.Entity != "" {
+		filter["entity"] = q.Entity
+	}
+	if q.EntityID != "" {
+		filter["entity_id"] = q.EntityID
+	}
+	if q.ActorID != nil {
+		filter["actor_id"] = *q.ActorID
+	}
+	created := bson.M{}
+	if q.From != nil {
+		created["$gte"] = *q.From
+	}
+	if q.To != nil {
+		created["$lt"] = *q.To
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(q.Offset)).
+		SetLimit(int64(q.Limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	page := &models.AuditPage{Events: []models.AuditEvent{}, Total: total, Limit: q.Limit, Offset: q.Offset}
+	if err := cursor.All(ctx, &page.Events); err != nil {
+		return nil, err
+	}
+	if page.Events == nil {
+		page.Events = []models.AuditEvent{}
+	}
+	return page, nil
+}
+
+func (r *MongoAuditRepo) PurgeEvents(before time.Time) (int64, error) {
+	ctx := context.Background()
+	res, err := r.DB.Database("hariomtransport").Collection("audit_event").
+		DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresAuditRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresAuditRepo(db *sql.DB) *PostgresAuditRepo {
+	return &PostgresAuditRepo{DB: db}
+}
+
+func (r *PostgresAuditRepo) AddEvent(e *models.AuditEvent) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return err
+	}
+	return r.DB.QueryRow(`
+		INSERT INTO audit_event (action, entity, entity_id, actor_id, actor_name, ip, user_agent, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, e.Action, e.Entity, e.EntityID, e.ActorID, e.ActorName, e.IP, e.UserAgent, payload, e.CreatedAt).Scan(&e.ID)
+}
+
+func (r *PostgresAuditRepo) ListEvents(q *models.AuditQuery) (*models.AuditPage, error) {
+	args := []interface{}{}
+	where := []string{"TRUE"}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if q.Action != "" {
+		add("action = $%d", q.Action)
+	}
+	if q.Entity != "" {
+		add("entity = $%d", q.Entity)
+	}
+	if q.EntityID != "" {
+		add("entity_id = $%d", q.EntityID)
+	}
+	if q.ActorID != nil {
+		add("actor_id = $%d", *q.ActorID)
+	}
+	if q.From != nil {
+		add("created_at >= $%d", *q.From)
+	}
+	if q.To != nil {
+		add("created_at < $%d", *q.To)
+	}
+	cond := strings.Join(where, " AND ")
+
+	page := &models.AuditPage{Events: []models.AuditEvent{}, Limit: q.Limit, Offset: q.Offset}
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM audit_event WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	rows, err := r.DB.Query(fmt.Sprintf(`
+		SELECT id, action, entity, entity_id, actor_id, actor_name, ip, user_agent, payload, created_at
+		FROM audit_event
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, cond, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.AuditEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Action, &e.Entity, &e.EntityID, &e.ActorID, &e.ActorName, &e.IP, &e.UserAgent,
+			&payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &e.Payload); err != nil {
+				return nil, err
+			}
+		}
+		page.Events = append(page.Events, e)
+	}
+	return page, rows.Err()
+}
+
+func (r *PostgresAuditRepo) PurgeEvents(before time.Time) (int64, error) {
+	res, err := r.DB.Exec(`DELETE FROM audit_event WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
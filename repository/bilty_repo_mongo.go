@@ -20,14 +20,74 @@ func NewMongoBiltyRepo(db *mongo.Client) *MongoBiltyRepo {
 	return &MongoBiltyRepo{DB: db}
 }
 
-// CreateBiltyWithParties inserts a bilty document with nested companies, addresses, and goods
+// CreateBiltyWithParties inserts a bilty document with nested companies, addresses, and goods.
+// A bilty with the ID of a stored one replaces it.
 func (r *MongoBiltyRepo) CreateBiltyWithParties(bilty *models.Bilty) error {
 	ctx := context.Background()
 	db := r.DB.Database("hariomtransport")
 
+	// Bookkeeping the client doesn't edit: the stored PDF, reprints and Tally
+	// export. Updates keep the stored values since the document is replaced
+	// whole, as Postgres's UPDATE leaves these columns alone.
+	bilty.PdfPath = nil
+	bilty.PdfHash = nil
+	bilty.PdfCreatedAt = nil
+	bilty.ReprintCount = 0
+	bilty.TallyExportedAt = nil
+	bilty.TallyExportID = nil
+
+	// The stored version, to record what the update changes
+	var before *models.Bilty
+	if bilty.ID != 0 {
+		found, err := r.GetBilty(map[string]interface{}{"id": bilty.ID}, true)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			before = found[0]
+			if bilty.DeliveryStatus == "" {
+				bilty.DeliveryStatus = before.DeliveryStatus
+			}
+			if bilty.PaymentType == "" {
+				bilty.PaymentType = before.PaymentType
+			}
+			bilty.BiltyNo = before.BiltyNo
+			bilty.CreatedBy = before.CreatedBy
+			bilty.CreatedAt = before.CreatedAt
+			bilty.PdfPath = before.PdfPath
+			bilty.PdfHash = before.PdfHash
+			bilty.PdfCreatedAt = before.PdfCreatedAt
+			bilty.ReprintCount = before.ReprintCount
+			bilty.TallyExportedAt = before.TallyExportedAt
+			bilty.TallyExportID = before.TallyExportID
+			now := time.Now().UTC()
+			bilty.UpdatedAt = &now
+		}
+	}
+
 	if bilty.CreatedAt.IsZero() {
 		bilty.CreatedAt = time.Now().UTC()
 	}
+	if bilty.DeliveryStatus == "" {
+		bilty.DeliveryStatus = "booked"
+	}
+	if bilty.PaymentType == "" {
+		bilty.PaymentType = models.PaymentToPay
+	}
+	if bilty.ID == 0 {
+		id, err := nextSequence(ctx, db, "bilty")
+		if err != nil {
+			return err
+		}
+		bilty.ID = id
+	}
+	if bilty.BiltyNo == 0 {
+		no, err := nextSequence(ctx, db, "bilty_no")
+		if err != nil {
+			return err
+		}
+		bilty.BiltyNo = no
+	}
 
 	// Upsert app_user if provided
 	if bilty.CreatedByUser != nil {
@@ -93,34 +153,148 @@ func (r *MongoBiltyRepo) CreateBiltyWithParties(bilty *models.Bilty) error {
 		return err
 	}
 
-	// Insert main bilty
-	_, err := db.Collection("bilty").InsertOne(ctx, bilty)
+	// Flag freight charged below the party's rate card
+	cards, err := (&MongoRateCardRepo{DB: r.DB}).RateCardsFor(models.RateCardParties(bilty), bilty.Date)
 	if err != nil {
 		return err
 	}
+	bilty.BelowContract = models.QuoteBilty(bilty, cards).BelowContract
 
-	// Insert goods
-	for _, g := range bilty.Goods {
-		g.BiltyID = bilty.ID
-		_, err := db.Collection("goods").InsertOne(ctx, g)
-		if err != nil {
+	// Update or insert main bilty
+	if before != nil {
+		if _, err := db.Collection("bilty").ReplaceOne(ctx, bson.M{"_id": bilty.ID}, bilty); err != nil {
 			return err
 		}
+	} else {
+		if _, err := db.Collection("bilty").InsertOne(ctx, bilty); err != nil {
+			return err
+		}
+	}
+
+	// Save goods against the stored lines
+	var storedGoods []models.Goods
+	if before != nil {
+		storedGoods = before.Goods
+	}
+	if err := r.saveGoods(ctx, db, bilty.ID, storedGoods, bilty.Goods); err != nil {
+		return err
 	}
 
+	// Record the change, diffing what is now stored
+	saved, err := r.GetBilty(map[string]interface{}{"id": bilty.ID}, true)
+	if err != nil {
+		return err
+	}
+	if len(saved) > 0 {
+		return r.recordChange(before, saved[0])
+	}
 	return nil
 }
 
+// saveGoods updates lines that are already stored, inserts new ones and
+// deletes the ones left out, so line IDs survive an edit. Lines saved before
+// goods had IDs are stored with ID 0; they are replaced by fresh lines.
+func (r *MongoBiltyRepo) saveGoods(ctx context.Context, db *mongo.Database, biltyID int64, stored, goods []models.Goods) error {
+	removed, err := planGoods(biltyID, stored, goods)
+	if err != nil {
+		return err
+	}
+
+	coll := db.Collection("goods")
+	if len(removed) > 0 {
+		if _, err := coll.DeleteMany(ctx, bson.M{"bilty_id": biltyID, "id": bson.M{"$in": removed}}); err != nil {
+			return err
+		}
+	}
+
+	for i := range goods {
+		g := &goods[i]
+		if g.ID != 0 {
+			if _, err := coll.ReplaceOne(ctx, bson.M{"bilty_id": biltyID, "id": g.ID}, g); err != nil {
+				return err
+			}
+			continue
+		}
+		id, err := nextSequence(ctx, db, "goods")
+		if err != nil {
+			return err
+		}
+		g.ID = id
+		if _, err := coll.InsertOne(ctx, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordChange appends the history entry for saving after over before (nil for a new bilty)
+func (r *MongoBiltyRepo) recordChange(before, after *models.Bilty) error {
+	changes := models.DiffBilty(before, after)
+	h := &models.BiltyHistory{BiltyID: after.ID, Changes: changes}
+	if before == nil {
+		h.Action = models.HistoryCreate
+		if after.CreatedBy != 0 {
+			h.UserID = &after.CreatedBy
+		}
+	} else {
+		if len(changes) == 0 {
+			return nil
+		}
+		h.Action = models.HistoryAction(changes)
+		h.UserID = after.UpdatedBy
+	}
+	return r.AddHistory(h)
+}
+
+// AddHistory appends an entry to a bilty's audit trail
+func (r *MongoBiltyRepo) AddHistory(h *models.BiltyHistory) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now().UTC()
+	}
+	id, err := nextSequence(ctx, db, "bilty_history")
+	if err != nil {
+		return err
+	}
+	h.ID = id
+	_, err = db.Collection("bilty_history").InsertOne(ctx, h)
+	return err
+}
+
+// BiltyHistory lists a bilty's audit trail, oldest first
+func (r *MongoBiltyRepo) BiltyHistory(biltyID int64) ([]models.BiltyHistory, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	// Decode nested old/new values as maps so they serialise back to plain JSON
+	coll := db.Collection("bilty_history", options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+	cur, err := coll.Find(ctx, bson.M{"bilty_id": biltyID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var history []models.BiltyHistory
+	if err := cur.All(ctx, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
 // GetBilty fetches bilties from MongoDB; single=true fetches one record
 func (r *MongoBiltyRepo) GetBilty(filters map[string]interface{}, single bool) ([]*models.Bilty, error) {
 	ctx := context.Background()
 	db := r.DB.Database("hariomtransport")
 
 	bsonFilter := bson.M{}
-	if filters != nil {
-		for k, v := range filters {
-			bsonFilter[k] = v
+	for k, v := range filters {
+		if k == "id" {
+			k = "_id"
 		}
+		bsonFilter[k] = v
 	}
 
 	var cur *mongo.Cursor
@@ -185,7 +359,8 @@ func (r *MongoBiltyRepo) populateNested(b *models.Bilty, ctx context.Context, db
 		b.CreatedByUser = &u
 	}
 	// Goods
-	goodsCur, _ := db.Collection("goods").Find(ctx, bson.M{"bilty_id": b.ID})
+	goodsCur, _ := db.Collection("goods").Find(ctx, bson.M{"bilty_id": b.ID},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: 1}, {Key: "id", Value: 1}}))
 	var goodsList []models.Goods
 	for goodsCur.Next(ctx) {
 		var g models.Goods
@@ -200,7 +375,7 @@ func (r *MongoBiltyRepo) populateNested(b *models.Bilty, ctx context.Context, db
 func (r *MongoBiltyRepo) GetBiltyByID(id int64) (*models.Bilty, error) {
 	db := r.DB.Database("hariomtransport")
 	collection := db.Collection("bilty")
-	filter := bson.M{"id": id}
+	filter := bson.M{"_id": id}
 
 	var bilty models.Bilty
 	err := collection.FindOne(context.Background(), filter).Decode(&bilty)
@@ -213,14 +388,15 @@ func (r *MongoBiltyRepo) GetBiltyByID(id int64) (*models.Bilty, error) {
 	return &bilty, nil
 }
 
-func (r *MongoBiltyRepo) UpdatePDFInfo(id int64, path string, createdAt time.Time) error {
+func (r *MongoBiltyRepo) UpdatePDFInfo(id int64, path, hash string, createdAt time.Time) error {
 	db := r.DB.Database("hariomtransport")
 	collection := db.Collection("bilty")
 
-	filter := bson.M{"id": id}
+	filter := bson.M{"_id": id}
 	update := bson.M{
 		"$set": bson.M{
 			"pdf_path":       path,
+			"pdf_hash":       hash,
 			"pdf_created_at": createdAt,
 		},
 	}
@@ -229,6 +405,61 @@ func (r *MongoBiltyRepo) UpdatePDFInfo(id int64, path string, createdAt time.Tim
 	return err
 }
 
+// IncrementReprintCount records one more reprint of an issued bilty
+func (r *MongoBiltyRepo) IncrementReprintCount(biltyID int64) error {
+	db := r.DB.Database("hariomtransport")
+
+	_, err := db.Collection("bilty").UpdateOne(context.Background(),
+		bson.M{"_id": biltyID},
+		bson.M{"$inc": bson.M{"reprint_count": 1}},
+	)
+	return err
+}
+
+func (r *MongoBiltyRepo) GetPDFVariant(biltyID int64, variant string) (*models.BiltyPDF, error) {
+	db := r.DB.Database("hariomtransport")
+
+	var p models.BiltyPDF
+	err := db.Collection("bilty_pdf").FindOne(context.Background(), bson.M{"bilty_id": biltyID, "variant": variant}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *MongoBiltyRepo) SavePDFVariant(p *models.BiltyPDF) error {
+	db := r.DB.Database("hariomtransport")
+
+	_, err := db.Collection("bilty_pdf").ReplaceOne(context.Background(),
+		bson.M{"bilty_id": p.BiltyID, "variant": p.Variant},
+		p,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *MongoBiltyRepo) PDFLocations() ([]string, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	var locations []string
+	for _, coll := range []string{"bilty", "bilty_pdf"} {
+		values, err := db.Collection(coll).Distinct(ctx, "pdf_path", bson.M{"pdf_path": bson.M{"$type": "string"}})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if loc, ok := v.(string); ok {
+				locations = append(locations, loc)
+			}
+		}
+	}
+	return locations, nil
+}
+
 func (r *MongoBiltyRepo) DeleteBilty(biltyID int64) error {
 	ctx := context.Background()
 	db := r.DB.Database("hariomtransport")
@@ -290,3 +521,84 @@ func (r *MongoBiltyRepo) DeleteBilty(biltyID int64) error {
 
 	return nil
 }
+
+// QueryBilties fetches bilties matching a structured query, oldest first
+func (r *MongoBiltyRepo) QueryBilties(q *models.BiltyQuery) ([]*models.Bilty, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	cur, err := db.Collection("bilty").Find(ctx, biltyQueryFilter(q),
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "bilty_no", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []*models.Bilty
+	for cur.Next(ctx) {
+		var b models.Bilty
+		if err := cur.Decode(&b); err != nil {
+			return nil, err
+		}
+		out = append(out, r.populateNested(&b, ctx, db))
+	}
+	return out, cur.Err()
+}
+
+func (r *MongoBiltyRepo) EachBilty(q *models.BiltyQuery, fn func(*models.Bilty) error) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	cur, err := db.Collection("bilty").Find(ctx, biltyQueryFilter(q),
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "_id", Value: 1}}).SetBatchSize(500))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var b models.Bilty
+		if err := cur.Decode(&b); err != nil {
+			return err
+		}
+		if err := fn(r.populateNested(&b, ctx, db)); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// biltyQueryFilter turns a BiltyQuery into a filter on bilty documents
+func biltyQueryFilter(q *models.BiltyQuery) bson.M {
+	filter := bson.M{}
+	if len(q.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": q.IDs}
+	}
+	if q.DateFrom != nil || q.DateTo != nil {
+		dateRange := bson.M{}
+		if q.DateFrom != nil {
+			dateRange["$gte"] = *q.DateFrom
+		}
+		if q.DateTo != nil {
+			// Dates are stored with their time of day; the whole last day counts
+			dateRange["$lt"] = q.DateTo.Add(24 * time.Hour)
+		}
+		filter["date"] = dateRange
+	}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+	if q.FromLocation != "" {
+		filter["from_location"] = q.FromLocation
+	}
+	if q.ToLocation != "" {
+		filter["to_location"] = q.ToLocation
+	}
+	if q.ConsignorCompanyID != nil {
+		filter["consignor_company_id"] = *q.ConsignorCompanyID
+	}
+	if q.ConsigneeCompanyID != nil {
+		filter["consignee_company_id"] = *q.ConsigneeCompanyID
+	}
+	return filter
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/hariomtransport/backend/models"
+	"github.com/lib/pq"
 )
 
 type PostgresBiltyRepo struct {
@@ -20,6 +22,16 @@ func NewPostgresBiltyRepo(db *sql.DB) *PostgresBiltyRepo {
 
 // ------------------------ Helper Functions ------------------------
 
+// queryer is what selectBilties needs; both *sql.DB and *sql.Tx provide it
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// execer runs a statement on a *sql.DB or inside a *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
 // Upsert AppUser
 func (r *PostgresBiltyRepo) upsertUser(tx *sql.Tx, u *models.AppUser) error {
 	if u.CreatedAt.IsZero() {
@@ -94,14 +106,53 @@ func (r *PostgresBiltyRepo) findOrInsertCompanyAddress(tx *sql.Tx, addr *models.
 	return newID, err
 }
 
-// Insert goods
-func (r *PostgresBiltyRepo) insertGoods(tx *sql.Tx, biltyID int64, goods []models.Goods) error {
+// Save goods: update lines that are already stored, insert new ones and
+// delete the ones left out, so line IDs survive an edit
+func (r *PostgresBiltyRepo) saveGoods(tx *sql.Tx, biltyID int64, goods []models.Goods) error {
+	// Lock the stored lines so a concurrent save can't change them underneath
+	rows, err := tx.Query(`SELECT id FROM goods WHERE bilty_id=$1 FOR UPDATE`, biltyID)
+	if err != nil {
+		return err
+	}
+	var stored []models.Goods
+	for rows.Next() {
+		var g models.Goods
+		if err := rows.Scan(&g.ID); err != nil {
+			rows.Close()
+			return err
+		}
+		stored = append(stored, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	removed, err := planGoods(biltyID, stored, goods)
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		if _, err := tx.Exec(`DELETE FROM goods WHERE bilty_id=$1 AND id = ANY($2)`, biltyID, pq.Array(removed)); err != nil {
+			return err
+		}
+	}
+
 	for i := range goods {
 		g := &goods[i]
-		_, err := tx.Exec(`
-			INSERT INTO goods(bilty_id,particulars,num_of_pkts,weight_kg,rate,per,amount)
-			VALUES($1,$2,$3,$4,$5,$6,$7)
-		`, biltyID, g.Particulars, g.NumOfPkts, g.WeightKG, g.Rate, g.Per, g.Amount)
+		if g.ID != 0 {
+			_, err = tx.Exec(`
+				UPDATE goods SET seq=$1, particulars=$2, num_of_pkts=$3, weight_kg=$4, rate=$5, per=$6, amount=$7, commodity_id=$8
+				WHERE id=$9 AND bilty_id=$10
+			`, g.Seq, g.Particulars, g.NumOfPkts, g.WeightKG, g.Rate, g.Per, g.Amount, g.CommodityID, g.ID, biltyID)
+		} else {
+			err = tx.QueryRow(`
+				INSERT INTO goods(bilty_id,seq,particulars,num_of_pkts,weight_kg,rate,per,amount,commodity_id)
+				VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+				RETURNING id
+			`, biltyID, g.Seq, g.Particulars, g.NumOfPkts, g.WeightKG, g.Rate, g.Per, g.Amount, g.CommodityID).Scan(&g.ID)
+		}
 		if err != nil {
 			return err
 		}
@@ -114,22 +165,30 @@ func (r *PostgresBiltyRepo) insertBiltyMain(tx *sql.Tx, bilty *models.Bilty) err
 	if bilty.CreatedAt.IsZero() {
 		bilty.CreatedAt = time.Now().UTC()
 	}
+	if bilty.DeliveryStatus == "" {
+		bilty.DeliveryStatus = "booked"
+	}
+	if bilty.PaymentType == "" {
+		bilty.PaymentType = models.PaymentToPay
+	}
 	return tx.QueryRow(`
 		INSERT INTO bilty(
 			consignor_company_id,consignee_company_id,
 			consignor_address_id,consignee_address_id,
 			from_location,to_location,date,to_pay,gstin,inv_no,pvt_marks,permit_no,
 			value_rupees,remarks,hamali,dd_charges,other_charges,fov,statistical,
-			created_by,created_at,status
+			created_by,created_at,status,delivery_status,place_of_supply,place_of_supply_code,
+			payment_type,gst_amount
 		)
-		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27)
 		RETURNING id,bilty_no
 	`,
 		bilty.ConsignorCompanyID, bilty.ConsigneeCompanyID, bilty.ConsignorAddressID, bilty.ConsigneeAddressID,
 		bilty.FromLocation, bilty.ToLocation, bilty.Date, bilty.ToPay, bilty.GSTIN, bilty.InvNo,
 		bilty.PVTMarks, bilty.PermitNo, bilty.ValueRupees, bilty.Remarks, bilty.Hamali,
 		bilty.DDCharges, bilty.OtherCharges, bilty.FOV, bilty.Statistical, bilty.CreatedBy,
-		bilty.CreatedAt, bilty.Status,
+		bilty.CreatedAt, bilty.Status, bilty.DeliveryStatus, bilty.PlaceOfSupply, bilty.PlaceOfSupplyCode,
+		bilty.PaymentType, bilty.GSTAmount,
 	).Scan(&bilty.ID, &bilty.BiltyNo)
 }
 
@@ -210,6 +269,25 @@ func (r *PostgresBiltyRepo) handleBiltyAddress(
 // ------------------------ Create / Update Bilty ------------------------
 
 func (r *PostgresBiltyRepo) CreateBiltyWithParties(bilty *models.Bilty) error {
+	// The stored version, to record what the update changes
+	var before *models.Bilty
+	if bilty.ID != 0 {
+		found, err := r.selectBilties(r.DB, []string{"b.id = $1"}, []interface{}{bilty.ID}, "")
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("bilty %d not found", bilty.ID)
+		}
+		before = found[0]
+		if bilty.DeliveryStatus == "" {
+			bilty.DeliveryStatus = before.DeliveryStatus
+		}
+		if bilty.PaymentType == "" {
+			bilty.PaymentType = before.PaymentType
+		}
+	}
+
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
@@ -399,31 +477,124 @@ func (r *PostgresBiltyRepo) CreateBiltyWithParties(bilty *models.Bilty) error {
 			status=$18,
 			updated_at=$19,
 			consignor_address_id=$20,
-			consignee_address_id=$21
-		WHERE id=$22
+			consignee_address_id=$21,
+			delivery_status=COALESCE(NULLIF($22, ''), delivery_status),
+			updated_by=$23,
+			place_of_supply=$24,
+			place_of_supply_code=$25,
+			payment_type=$26,
+			gst_amount=$27
+		WHERE id=$28
 	`,
 			bilty.ConsignorCompanyID, bilty.ConsigneeCompanyID,
 			bilty.FromLocation, bilty.ToLocation, bilty.Date, bilty.ToPay, bilty.GSTIN,
 			bilty.InvNo, bilty.PVTMarks, bilty.PermitNo, bilty.ValueRupees, bilty.Remarks,
 			bilty.Hamali, bilty.DDCharges, bilty.OtherCharges, bilty.FOV, bilty.Statistical,
-			bilty.Status, time.Now().UTC(), bilty.ConsignorAddressID, bilty.ConsigneeAddressID, bilty.ID,
+			bilty.Status, time.Now().UTC(), bilty.ConsignorAddressID, bilty.ConsigneeAddressID,
+			bilty.DeliveryStatus, bilty.UpdatedBy, bilty.PlaceOfSupply, bilty.PlaceOfSupplyCode,
+			bilty.PaymentType, bilty.GSTAmount, bilty.ID,
 		)
 		if err != nil {
 			return err
 		}
+	}
+
+	// Save goods against the stored lines
+	if err := r.saveGoods(tx, bilty.ID, bilty.Goods); err != nil {
+		return err
+	}
 
-		// Refresh goods
-		if _, err := tx.Exec(`DELETE FROM goods WHERE bilty_id=$1`, bilty.ID); err != nil {
+	// Flag freight charged below the party's rate card
+	cards, err := rateCardsFor(tx, models.RateCardParties(bilty), bilty.Date)
+	if err != nil {
+		return err
+	}
+	bilty.BelowContract = models.QuoteBilty(bilty, cards).BelowContract
+	if _, err := tx.Exec(`UPDATE bilty SET below_contract=$1 WHERE id=$2`, bilty.BelowContract, bilty.ID); err != nil {
+		return err
+	}
+
+	// Record the change, diffing what is now stored
+	saved, err := r.selectBilties(tx, []string{"b.id = $1"}, []interface{}{bilty.ID}, "")
+	if err != nil {
+		return err
+	}
+	if len(saved) > 0 {
+		if err := r.recordChange(tx, before, saved[0]); err != nil {
 			return err
 		}
 	}
 
-	// Insert goods
-	if err := r.insertGoods(tx, bilty.ID, bilty.Goods); err != nil {
+	return tx.Commit()
+}
+
+// recordChange appends the history entry for saving after over before (nil for a new bilty)
+func (r *PostgresBiltyRepo) recordChange(db execer, before, after *models.Bilty) error {
+	changes := models.DiffBilty(before, after)
+	h := &models.BiltyHistory{BiltyID: after.ID, Changes: changes}
+	if before == nil {
+		h.Action = models.HistoryCreate
+		if after.CreatedBy != 0 {
+			h.UserID = &after.CreatedBy
+		}
+	} else {
+		if len(changes) == 0 {
+			return nil
+		}
+		h.Action = models.HistoryAction(changes)
+		h.UserID = after.UpdatedBy
+	}
+	return r.addHistory(db, h)
+}
+
+// AddHistory appends an entry to a bilty's audit trail
+func (r *PostgresBiltyRepo) AddHistory(h *models.BiltyHistory) error {
+	return r.addHistory(r.DB, h)
+}
+
+func (r *PostgresBiltyRepo) addHistory(db execer, h *models.BiltyHistory) error {
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now().UTC()
+	}
+	changes, err := json.Marshal(h.Changes)
+	if err != nil {
 		return err
 	}
+	_, err = db.Exec(`
+		INSERT INTO bilty_history (bilty_id, action, user_id, changes, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, h.BiltyID, h.Action, h.UserID, changes, h.CreatedAt)
+	return err
+}
 
-	return tx.Commit()
+// BiltyHistory lists a bilty's audit trail, oldest first
+func (r *PostgresBiltyRepo) BiltyHistory(biltyID int64) ([]models.BiltyHistory, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, bilty_id, action, user_id, changes, created_at
+		FROM bilty_history
+		WHERE bilty_id = $1
+		ORDER BY created_at, id
+	`, biltyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.BiltyHistory
+	for rows.Next() {
+		var h models.BiltyHistory
+		var changes []byte
+		if err := rows.Scan(&h.ID, &h.BiltyID, &h.Action, &h.UserID, &changes, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &h.Changes); err != nil {
+				return nil, err
+			}
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
 }
 
 func (r *PostgresBiltyRepo) hasAddressChanged(tx *sql.Tx, existingID int64, newAddr *models.BiltyAddress) (bool, error) {
@@ -452,13 +623,114 @@ func (r *PostgresBiltyRepo) hasAddressChanged(tx *sql.Tx, existingID int64, newA
 // ------------------------ GetBilty ------------------------
 
 func (r *PostgresBiltyRepo) GetBilty(filters map[string]interface{}, single bool) ([]*models.Bilty, error) {
+	args := []interface{}{}
+	where := []string{}
+	i := 1
+	for k, v := range filters {
+		where = append(where, fmt.Sprintf("b.%s = $%d", k, i))
+		args = append(args, v)
+		i++
+	}
+	order := ""
+	if !single {
+		order = "b.created_at DESC"
+	}
+
+	result, err := r.selectBilties(r.DB, where, args, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if single {
+		if len(result) > 0 {
+			return []*models.Bilty{result[0]}, nil
+		}
+		return nil, nil
+	}
+	return result, nil
+}
+
+// QueryBilties fetches bilties matching a structured query, oldest first
+func (r *PostgresBiltyRepo) QueryBilties(q *models.BiltyQuery) ([]*models.Bilty, error) {
+	where, args := biltyQueryWhere(q)
+	return r.selectBilties(r.DB, where, args, "b.date, b.bilty_no")
+}
+
+// eachBiltyBatch is how many bilties EachBilty loads per query
+const eachBiltyBatch = 500
+
+func (r *PostgresBiltyRepo) EachBilty(q *models.BiltyQuery, fn func(*models.Bilty) error) error {
+	where, args := biltyQueryWhere(q)
+	var last *models.Bilty
+	for {
+		// Keyset paging on (date, id) picks up after the last bilty seen
+		w, a := where, args
+		if last != nil {
+			a = append(args[:len(args):len(args)], last.Date, last.ID)
+			w = append(where[:len(where):len(where)], fmt.Sprintf("(b.date, b.id) > ($%d, $%d)", len(a)-1, len(a)))
+		}
+		batch, err := r.selectBilties(r.DB, w, a, fmt.Sprintf("b.date, b.id LIMIT %d", eachBiltyBatch))
+		if err != nil {
+			return err
+		}
+		for _, b := range batch {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+		if len(batch) < eachBiltyBatch {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
+// biltyQueryWhere turns a BiltyQuery into conditions on the bilty table aliased b
+func biltyQueryWhere(q *models.BiltyQuery) ([]string, []interface{}) {
+	args := []interface{}{}
+	where := []string{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(q.IDs) > 0 {
+		add("b.id = ANY($%d)", pq.Array(q.IDs))
+	}
+	if q.DateFrom != nil {
+		add("b.date >= $%d", *q.DateFrom)
+	}
+	if q.DateTo != nil {
+		add("b.date <= $%d", *q.DateTo)
+	}
+	if q.Status != "" {
+		add("b.status = $%d", q.Status)
+	}
+	if q.FromLocation != "" {
+		add("b.from_location = $%d", q.FromLocation)
+	}
+	if q.ToLocation != "" {
+		add("b.to_location = $%d", q.ToLocation)
+	}
+	if q.ConsignorCompanyID != nil {
+		add("b.consignor_company_id = $%d", *q.ConsignorCompanyID)
+	}
+	if q.ConsigneeCompanyID != nil {
+		add("b.consignee_company_id = $%d", *q.ConsigneeCompanyID)
+	}
+	return where, args
+}
+
+// selectBilties loads bilties with their companies, address snapshots, creator and goods
+func (r *PostgresBiltyRepo) selectBilties(db queryer, where []string, args []interface{}, order string) ([]*models.Bilty, error) {
 	query := `
 		SELECT 
 			b.id, b.bilty_no, b.consignor_company_id, b.consignee_company_id,
 			b.consignor_address_id, b.consignee_address_id,
 			b.from_location, b.to_location, b.date, b.to_pay, b.gstin, b.inv_no, b.pvt_marks, b.permit_no,
 			b.value_rupees, b.remarks, b.hamali, b.dd_charges, b.other_charges, b.fov, b.statistical,
-			b.created_by, b.created_at, b.status,
+			b.created_by, b.created_at, b.status, b.delivery_status, b.updated_at, b.updated_by, b.pdf_created_at, b.pdf_path, b.pdf_hash, b.reprint_count, b.below_contract,
+			b.place_of_supply, b.place_of_supply_code, b.payment_type, b.gst_amount, b.tally_exported_at, b.tally_export_id,
 
 			-- Consignor company
 			cc1.id, cc1.name, cc1.gstin, cc1.created_at,
@@ -480,22 +752,14 @@ func (r *PostgresBiltyRepo) GetBilty(filters map[string]interface{}, single bool
 		LEFT JOIN app_user u ON b.created_by = u.id
 	`
 
-	args := []interface{}{}
-	where := []string{}
-	i := 1
-	for k, v := range filters {
-		where = append(where, fmt.Sprintf("b.%s = $%d", k, i))
-		args = append(args, v)
-		i++
-	}
 	if len(where) > 0 {
 		query += " WHERE " + strings.Join(where, " AND ")
 	}
-	if !single {
-		query += " ORDER BY b.created_at DESC"
+	if order != "" {
+		query += " ORDER BY " + order
 	}
 
-	rows, err := r.DB.Query(query, args...)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -514,7 +778,8 @@ func (r *PostgresBiltyRepo) GetBilty(filters map[string]interface{}, single bool
 			&b.FromLocation, &b.ToLocation, &b.Date, &b.ToPay, &b.GSTIN, &b.InvNo,
 			&b.PVTMarks, &b.PermitNo, &b.ValueRupees, &b.Remarks,
 			&b.Hamali, &b.DDCharges, &b.OtherCharges, &b.FOV, &b.Statistical,
-			&b.CreatedBy, &b.CreatedAt, &b.Status,
+			&b.CreatedBy, &b.CreatedAt, &b.Status, &b.DeliveryStatus, &b.UpdatedAt, &b.UpdatedBy, &b.PdfCreatedAt, &b.PdfPath, &b.PdfHash, &b.ReprintCount, &b.BelowContract,
+			&b.PlaceOfSupply, &b.PlaceOfSupplyCode, &b.PaymentType, &b.GSTAmount, &b.TallyExportedAt, &b.TallyExportID,
 
 			&consignorC.ID, &consignorC.Name, &consignorC.GSTIN, &consignorC.CreatedAt,
 			&consigneeC.ID, &consigneeC.Name, &consigneeC.GSTIN, &consigneeC.CreatedAt,
@@ -556,17 +821,18 @@ func (r *PostgresBiltyRepo) GetBilty(filters map[string]interface{}, single bool
 			idStrs[i] = fmt.Sprintf("$%d", i+1)
 		}
 		goodsQuery := fmt.Sprintf(`
-			SELECT id, bilty_id, particulars, num_of_pkts, weight_kg, rate, per, amount
+			SELECT id, bilty_id, seq, commodity_id, particulars, num_of_pkts, weight_kg, rate, per, amount
 			FROM goods
 			WHERE bilty_id IN (%s)
+			ORDER BY bilty_id, seq, id
 		`, strings.Join(idStrs, ","))
-		goodsRows, _ := r.DB.Query(goodsQuery, ids...)
+		goodsRows, _ := db.Query(goodsQuery, ids...)
 		defer goodsRows.Close()
 
 		goodsMap := make(map[int64][]models.Goods)
 		for goodsRows.Next() {
 			var g models.Goods
-			_ = goodsRows.Scan(&g.ID, &g.BiltyID, &g.Particulars, &g.NumOfPkts, &g.WeightKG, &g.Rate, &g.Per, &g.Amount)
+			_ = goodsRows.Scan(&g.ID, &g.BiltyID, &g.Seq, &g.CommodityID, &g.Particulars, &g.NumOfPkts, &g.WeightKG, &g.Rate, &g.Per, &g.Amount)
 			goodsMap[g.BiltyID] = append(goodsMap[g.BiltyID], g)
 		}
 
@@ -577,12 +843,6 @@ func (r *PostgresBiltyRepo) GetBilty(filters map[string]interface{}, single bool
 		}
 	}
 
-	if single {
-		if len(result) > 0 {
-			return []*models.Bilty{result[0]}, nil
-		}
-		return nil, nil
-	}
 	return result, nil
 }
 
@@ -593,13 +853,39 @@ func (r *PostgresBiltyRepo) UpdatePDFCreatedAt(biltyID int64, t time.Time) error
 	return err
 }
 
-func (r *PostgresBiltyRepo) UpdatePDFInfo(id int64, path string, createdAt time.Time) error {
+func (r *PostgresBiltyRepo) UpdatePDFInfo(id int64, path, hash string, createdAt time.Time) error {
 	query := `
 		UPDATE bilty
-		SET pdf_path = $1, pdf_created_at = $2
-		WHERE id = $3
+		SET pdf_path = $1, pdf_hash = $2, pdf_created_at = $3
+		WHERE id = $4
 	`
-	_, err := r.DB.Exec(query, path, createdAt, id)
+	_, err := r.DB.Exec(query, path, hash, createdAt, id)
+	return err
+}
+
+// GetPDFVariant returns the stored PDF for one print variant, or nil
+func (r *PostgresBiltyRepo) GetPDFVariant(biltyID int64, variant string) (*models.BiltyPDF, error) {
+	p := models.BiltyPDF{BiltyID: biltyID, Variant: variant}
+	err := r.DB.QueryRow(`
+		SELECT pdf_path, COALESCE(pdf_hash, ''), created_at FROM bilty_pdf WHERE bilty_id = $1 AND variant = $2
+	`, biltyID, variant).Scan(&p.PdfPath, &p.Hash, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SavePDFVariant records (or replaces) the stored PDF for a print variant
+func (r *PostgresBiltyRepo) SavePDFVariant(p *models.BiltyPDF) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO bilty_pdf (bilty_id, variant, pdf_path, pdf_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (bilty_id, variant) DO UPDATE
+		SET pdf_path = EXCLUDED.pdf_path, pdf_hash = EXCLUDED.pdf_hash, created_at = EXCLUDED.created_at
+	`, p.BiltyID, p.Variant, p.PdfPath, p.Hash, p.CreatedAt)
 	return err
 }
 
@@ -666,14 +952,14 @@ func (r *PostgresBiltyRepo) DeleteBilty(biltyID int64) error {
 
 func (r *PostgresBiltyRepo) GetBiltyByID(id int64) (*models.Bilty, error) {
 	query := `
-		SELECT id, updated_at, pdf_created_at, pdf_path
+		SELECT id, status, updated_at, pdf_created_at, pdf_path, pdf_hash, reprint_count
 		FROM bilty
 		WHERE id = $1
 	`
 	row := r.DB.QueryRow(query, id)
 
 	var bilty models.Bilty
-	err := row.Scan(&bilty.ID, &bilty.UpdatedAt, &bilty.PdfCreatedAt, &bilty.PdfPath)
+	err := row.Scan(&bilty.ID, &bilty.Status, &bilty.UpdatedAt, &bilty.PdfCreatedAt, &bilty.PdfPath, &bilty.PdfHash, &bilty.ReprintCount)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -683,3 +969,31 @@ func (r *PostgresBiltyRepo) GetBiltyByID(id int64) (*models.Bilty, error) {
 
 	return &bilty, nil
 }
+
+// IncrementReprintCount records one more reprint of an issued bilty
+func (r *PostgresBiltyRepo) IncrementReprintCount(biltyID int64) error {
+	_, err := r.DB.Exec(`UPDATE bilty SET reprint_count = reprint_count + 1 WHERE id = $1`, biltyID)
+	return err
+}
+
+func (r *PostgresBiltyRepo) PDFLocations() ([]string, error) {
+	rows, err := r.DB.Query(`
+		SELECT pdf_path FROM bilty WHERE pdf_path IS NOT NULL
+		UNION
+		SELECT pdf_path FROM bilty_pdf
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []string
+	for rows.Next() {
+		var loc string
+		if err := rows.Scan(&loc); err != nil {
+			return nil, err
+		}
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
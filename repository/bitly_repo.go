@@ -9,7 +9,20 @@ import (
 type BiltyRepository interface {
 	CreateBiltyWithParties(bilty *models.Bilty) error
 	GetBilty(filters map[string]interface{}, single bool) ([]*models.Bilty, error)
-	UpdatePDFInfo(biltyID int64, pdfPath string, t time.Time) error
+	UpdatePDFInfo(biltyID int64, pdfPath, pdfHash string, t time.Time) error
 	DeleteBilty(biltyID int64) error
 	GetBiltyByID(biltyID int64) (*models.Bilty, error)
+	QueryBilties(q *models.BiltyQuery) ([]*models.Bilty, error)
+	// EachBilty calls fn for every bilty matching q in date order, reading
+	// them in batches so large ranges are never loaded at once
+	EachBilty(q *models.BiltyQuery, fn func(*models.Bilty) error) error
+	GetPDFVariant(biltyID int64, variant string) (*models.BiltyPDF, error)
+	SavePDFVariant(p *models.BiltyPDF) error
+	IncrementReprintCount(biltyID int64) error
+	// PDFLocations lists every stored PDF location the database refers to
+	PDFLocations() ([]string, error)
+	// AddHistory appends an entry to a bilty's audit trail
+	AddHistory(h *models.BiltyHistory) error
+	// BiltyHistory lists a bilty's audit trail, oldest first
+	BiltyHistory(biltyID int64) ([]models.BiltyHistory, error)
 }
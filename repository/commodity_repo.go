@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/hariomtransport/backend/models"
+
+// CommodityRepository manages the goods catalogue
+type CommodityRepository interface {
+	// SaveCommodity creates a commodity, or updates the one with its ID
+	SaveCommodity(c *models.Commodity) error
+	GetCommodity(id int64) (*models.Commodity, error)
+	// SearchCommodities matches names starting with the term first, then
+	// names containing it and HSN codes starting with it; an empty term lists all
+	SearchCommodities(term string, limit int) ([]models.Commodity, error)
+	// CommodityTotals groups the goods of the bilties matching q by commodity.
+	// Cancelled bilties are left out unless q asks for them by status.
+	CommodityTotals(q *models.BiltyQuery) ([]models.CommodityTotal, error)
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoCommodityRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoCommodityRepo(db *mongo.Client) *MongoCommodityRepo {
+	return &MongoCommodityRepo{DB: db}
+}
+
+func (r *MongoCommodityRepo) SaveCommodity(c *models.Commodity) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+	coll := db.Collection("commodity")
+
+	// Names are unique regardless of case, as in the Postgres index
+	filter := bson.M{"name": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(c.Name) + "$", Options: "i"}}
+	if c.ID != 0 {
+		filter["_id"] = bson.M{"$ne": c.ID}
+	}
+	if n, err := coll.CountDocuments(ctx, filter); err != nil {
+		return err
+	} else if n > 0 {
+		return fmt.Errorf("commodity %q already exists", c.Name)
+	}
+
+	now := time.Now().UTC()
+	if c.ID == 0 {
+		id, err := nextSequence(ctx, db, "commodity")
+		if err != nil {
+			return err
+		}
+		c.ID = id
+		c.CreatedAt = now
+		_, err = coll.InsertOne(ctx, c)
+		return err
+	}
+
+	existing, err := r.GetCommodity(c.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("commodity %d not found", c.ID)
+	}
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = &now
+	_, err = coll.ReplaceOne(ctx, bson.M{"_id": c.ID}, c)
+	return err
+}
+
+func (r *MongoCommodityRepo) GetCommodity(id int64) (*models.Commodity, error) {
+	ctx := context.Background()
+	var c models.Commodity
+	err := r.DB.Database("hariomtransport").Collection("commodity").FindOne(ctx, bson.M{"_id": id}).Decode(&c)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *MongoCommodityRepo) SearchCommodities(term string, limit int) ([]models.Commodity, error) {
+	ctx := context.Background()
+	term = strings.TrimSpace(term)
+
+	filter := bson.M{}
+	if term != "" {
+		quoted := regexp.QuoteMeta(term)
+		filter["$or"] = bson.A{
+			bson.M{"name": primitive.Regex{Pattern: quoted, Options: "i"}},
+			bson.M{"hsn_code": primitive.Regex{Pattern: "^" + quoted}},
+		}
+	}
+	cur, err := r.DB.Database("hariomtransport").Collection("commodity").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []models.Commodity{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	// Names starting with the term first, then alphabetical
+	lower := strings.ToLower(term)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := strings.ToLower(list[i].Name), strings.ToLower(list[j].Name)
+		if pa, pb := strings.HasPrefix(a, lower), strings.HasPrefix(b, lower); pa != pb {
+			return pa
+		}
+		return a < b
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	if list == nil {
+		list = []models.Commodity{}
+	}
+	return list, nil
+}
+
+func (r *MongoCommodityRepo) CommodityTotals(q *models.BiltyQuery) ([]models.CommodityTotal, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	biltyFilter := biltyQueryFilter(q)
+	if q.Status == "" {
+		biltyFilter["status"] = bson.M{"$ne": "cancelled"}
+	}
+
+	// Match the bilties first, so only their goods are joined
+	cur, err := db.Collection("bilty").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: biltyFilter}},
+		{{Key: "$lookup", Value: bson.M{"from": "goods", "localField": "_id", "foreignField": "bilty_id", "as": "goods"}}},
+		{{Key: "$unwind", Value: "$goods"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$goods.commodity_id",
+			"bilties":  bson.M{"$addToSet": "$_id"},
+			"packages": bson.M{"$sum": "$goods.num_of_pkts"},
+			"weight":   bson.M{"$sum": "$goods.weight_kg"},
+			"freight":  bson.M{"$sum": bson.M{"$toDecimal": bson.M{"$ifNull": bson.A{"$goods.amount", 0}}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var groups []struct {
+		CommodityID *int64       `bson:"_id"`
+		Bilties     []int64      `bson:"bilties"`
+		Packages    int64        `bson:"packages"`
+		Weight      float64      `bson:"weight"`
+		Freight     models.Money `bson:"freight"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	// Commodity names, fetched once for all groups
+	ids := []int64{}
+	for _, g := range groups {
+		if g.CommodityID != nil {
+			ids = append(ids, *g.CommodityID)
+		}
+	}
+	cc, err := db.Collection("commodity").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var commodities []models.Commodity
+	if err := cc.All(ctx, &commodities); err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Commodity, len(commodities))
+	for _, c := range commodities {
+		byID[c.ID] = c
+	}
+
+	totals := []models.CommodityTotal{}
+	for _, g := range groups {
+		t := models.CommodityTotal{
+			CommodityID: g.CommodityID,
+			Bilties:     int64(len(g.Bilties)),
+			Packages:    g.Packages,
+			WeightKG:    g.Weight,
+			Tonnes:      g.Weight / 1000,
+			Freight:     g.Freight,
+		}
+		if g.CommodityID != nil {
+			if c, ok := byID[*g.CommodityID]; ok {
+				t.Name, t.HSNCode = c.Name, c.HSNCode
+			}
+		}
+		totals = append(totals, t)
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].WeightKG != totals[j].WeightKG {
+			return totals[i].WeightKG > totals[j].WeightKG
+		}
+		return totals[i].Name < totals[j].Name
+	})
+	return totals, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresCommodityRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresCommodityRepo(db *sql.DB) *PostgresCommodityRepo {
+	return &PostgresCommodityRepo{DB: db}
+}
+
+const commodityColumns = `id, name, hsn_code, per, default_rate, created_at, updated_at`
+
+func scanCommodity(row rowScanner) (*models.Commodity, error) {
+	var c models.Commodity
+	if err := row.Scan(&c.ID, &c.Name, &c.HSNCode, &c.Per, &c.DefaultRate, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresCommodityRepo) SaveCommodity(c *models.Commodity) error {
+	now := time.Now().UTC()
+	if c.ID == 0 {
+		c.CreatedAt = now
+		return r.DB.QueryRow(`
+			INSERT INTO commodity (name, hsn_code, per, default_rate, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, c.Name, c.HSNCode, c.Per, c.DefaultRate, c.CreatedAt).Scan(&c.ID)
+	}
+
+	c.UpdatedAt = &now
+	err := r.DB.QueryRow(`
+		UPDATE commodity SET name=$1, hsn_code=$2, per=$3, default_rate=$4, updated_at=$5
+		WHERE id=$6
+		RETURNING created_at
+	`, c.Name, c.HSNCode, c.Per, c.DefaultRate, now, c.ID).Scan(&c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("commodity %d not found", c.ID)
+	}
+	return err
+}
+
+func (r *PostgresCommodityRepo) GetCommodity(id int64) (*models.Commodity, error) {
+	c, err := scanCommodity(r.DB.QueryRow(`SELECT `+commodityColumns+` FROM commodity WHERE id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *PostgresCommodityRepo) SearchCommodities(term string, limit int) ([]models.Commodity, error) {
+	term = strings.TrimSpace(term)
+	like := escapeLike(term)
+	rows, err := r.DB.Query(`
+		SELECT `+commodityColumns+`
+		FROM commodity
+		WHERE $1 = '' OR name ILIKE '%' || $2 || '%' OR hsn_code LIKE $2 || '%'
+		ORDER BY (name ILIKE $2 || '%') DESC, lower(name)
+		LIMIT $3
+	`, term, like, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Commodity{}
+	for rows.Next() {
+		c, err := scanCommodity(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *c)
+	}
+	return list, rows.Err()
+}
+
+// escapeLike makes s match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *PostgresCommodityRepo) CommodityTotals(q *models.BiltyQuery) ([]models.CommodityTotal, error) {
+	where, args := biltyQueryWhere(q)
+	if q.Status == "" {
+		where = append(where, "b.status <> 'cancelled'")
+	}
+	cond := "TRUE"
+	if len(where) > 0 {
+		cond = strings.Join(where, " AND ")
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT c.id, COALESCE(c.name, ''), c.hsn_code,
+			COUNT(DISTINCT b.id), COALESCE(SUM(g.num_of_pkts), 0),
+			COALESCE(SUM(g.weight_kg), 0), COALESCE(SUM(g.amount), 0)
+		FROM goods g
+		JOIN bilty b ON b.id = g.bilty_id
+		LEFT JOIN commodity c ON c.id = g.commodity_id
+		WHERE `+cond+`
+		GROUP BY c.id, c.name, c.hsn_code
+		ORDER BY SUM(g.weight_kg) DESC NULLS LAST, c.name
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []models.CommodityTotal{}
+	for rows.Next() {
+		var t models.CommodityTotal
+		if err := rows.Scan(&t.CommodityID, &t.Name, &t.HSNCode, &t.Bilties, &t.Packages, &t.WeightKG, &t.Freight); err != nil {
+			return nil, err
+		}
+		t.Tonnes = t.WeightKG / 1000
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// planGoods matches the submitted goods lines against the stored ones by ID.
+// It numbers the lines in order and returns the IDs of stored lines that were
+// left out and must be deleted. Lines with ID 0 are new; an ID belonging to
+// another bilty is an error.
+func planGoods(biltyID int64, stored, goods []models.Goods) ([]int64, error) {
+	keep := make(map[int64]bool, len(stored))
+	for _, g := range stored {
+		keep[g.ID] = false
+	}
+
+	for i := range goods {
+		g := &goods[i]
+		g.BiltyID = biltyID
+		g.Seq = i + 1
+		if g.ID == 0 {
+			continue
+		}
+		seen, ok := keep[g.ID]
+		if !ok {
+			return nil, fmt.Errorf("goods line %d does not belong to bilty %d", g.ID, biltyID)
+		}
+		if seen {
+			return nil, fmt.Errorf("goods line %d is listed twice", g.ID)
+		}
+		keep[g.ID] = true
+	}
+
+	var removed []int64
+	for _, g := range stored {
+		if !keep[g.ID] {
+			removed = append(removed, g.ID)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+func TestPlanGoods(t *testing.T) {
+	lines := func(ids ...int64) []models.Goods {
+		var out []models.Goods
+		for _, id := range ids {
+			out = append(out, models.Goods{ID: id, BiltyID: 7})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		stored      []models.Goods
+		goods       []models.Goods
+		wantRemoved []int64
+		wantErr     bool
+	}{
+		{"new bilty", nil, lines(0, 0), nil, false},
+		{"keep all", lines(1, 2), lines(1, 2), nil, false},
+		{"reorder", lines(1, 2, 3), lines(3, 1, 2), nil, false},
+		{"add a line", lines(1, 2), lines(1, 0, 2), nil, false},
+		{"drop a line", lines(1, 2, 3), lines(1, 3), []int64{2}, false},
+		{"drop every line", lines(1, 2), nil, []int64{1, 2}, false},
+		{"replace a line", lines(1, 2), lines(1, 0), []int64{2}, false},
+		{"legacy lines without IDs", lines(0, 0), lines(0), []int64{0, 0}, false},
+		{"line of another bilty", lines(1, 2), lines(1, 9), nil, true},
+		{"line listed twice", lines(1, 2), lines(1, 1), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed, err := planGoods(42, tt.stored, tt.goods)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("planGoods error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			for i, g := range tt.goods {
+				if g.BiltyID != 42 || g.Seq != i+1 {
+					t.Errorf("line %d has bilty %d seq %d, want bilty 42 seq %d", i, g.BiltyID, g.Seq, i+1)
+				}
+			}
+		})
+	}
+}
@@ -33,22 +33,35 @@ func (r *PostgresInitialRepo) SaveInitial(initial *models.InitialSetup) error {
 		return err
 	}
 
+	copiesJSON, err := json.Marshal(initial.Copies)
+	if err != nil {
+		return err
+	}
+
+	var layoutJSON []byte
+	if initial.TextLayout != nil {
+		if layoutJSON, err = json.Marshal(initial.TextLayout); err != nil {
+			return err
+		}
+	}
+
 	// If ID is passed → UPDATE, else INSERT
 	if initial.ID > 0 {
 		_, err = r.DB.Exec(`
 			UPDATE initial_setup
 			SET company_name=$1, gstin=$2, address=$3, city=$4, state=$5,
-				pincode=$6, mobile=$7, footnote=$8, created_at=$9
-			WHERE id=$10
+				pincode=$6, mobile=$7, footnote=$8, copies=$9, text_layout=$10,
+				language=COALESCE(NULLIF($11, ''), 'en'), created_at=$12
+			WHERE id=$13
 		`, initial.CompanyName, initial.GSTIN, initial.Address, initial.City, initial.State,
-			initial.Pincode, mobileJSON, footnoteJSON, initial.CreatedAt, initial.ID)
+			initial.Pincode, mobileJSON, footnoteJSON, copiesJSON, layoutJSON, initial.Language, initial.CreatedAt, initial.ID)
 	} else {
 		_, err = r.DB.Exec(`
 			INSERT INTO initial_setup 
-			(company_name, gstin, address, city, state, pincode, mobile, footnote, created_at)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			(company_name, gstin, address, city, state, pincode, mobile, footnote, copies, text_layout, language, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,COALESCE(NULLIF($11, ''), 'en'),$12)
 		`, initial.CompanyName, initial.GSTIN, initial.Address, initial.City, initial.State,
-			initial.Pincode, mobileJSON, footnoteJSON, initial.CreatedAt)
+			initial.Pincode, mobileJSON, footnoteJSON, copiesJSON, layoutJSON, initial.Language, initial.CreatedAt)
 	}
 
 	return err
@@ -59,13 +72,15 @@ func (r *PostgresInitialRepo) GetInitial() (*models.InitialSetup, error) {
 	initial := &models.InitialSetup{}
 	var mobileJSON []byte
 	var footnoteJSON []byte
+	var copiesJSON []byte
+	var layoutJSON []byte
 
 	err := r.DB.QueryRow(`
-		SELECT id, company_name, address, city, state, pincode, gstin, footnote, mobile, created_at
+		SELECT id, company_name, address, city, state, pincode, gstin, footnote, mobile, copies, text_layout, language, created_at
 		FROM initial_setup
 		ORDER BY id DESC LIMIT 1
 	`).Scan(&initial.ID, &initial.CompanyName, &initial.Address, &initial.City, &initial.State,
-		&initial.Pincode, &initial.GSTIN, &footnoteJSON, &mobileJSON, &initial.CreatedAt)
+		&initial.Pincode, &initial.GSTIN, &footnoteJSON, &mobileJSON, &copiesJSON, &layoutJSON, &initial.Language, &initial.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,5 +102,17 @@ func (r *PostgresInitialRepo) GetInitial() (*models.InitialSetup, error) {
 		}
 	}
 
+	if len(copiesJSON) > 0 {
+		if err := json.Unmarshal(copiesJSON, &initial.Copies); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(layoutJSON) > 0 {
+		if err := json.Unmarshal(layoutJSON, &initial.TextLayout); err != nil {
+			return nil, err
+		}
+	}
+
 	return initial, nil
 }
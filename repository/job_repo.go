@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// ErrJobLost means a worker no longer holds the job it tried to settle
+var ErrJobLost = errors.New("job was requeued after its lease expired")
+
+// JobRepository persists background PDF jobs so pending work survives restarts
+type JobRepository interface {
+	CreateJob(job *models.PDFJob) error
+	GetJob(id int64) (*models.PDFJob, error)
+	// ClaimJob marks the oldest due queued job as running and returns it, or nil if none is due
+	ClaimJob(now time.Time) (*models.PDFJob, error)
+	// CompleteJob, RetryJob and FailJob settle the claim made for the given
+	// attempt. They return ErrJobLost when the job is no longer running under
+	// that claim, because its lease expired and it was requeued.
+	CompleteJob(id int64, attempt int, result string) error
+	// RetryJob puts a job back in the queue to run again after runAfter
+	RetryJob(id int64, attempt int, errMsg string, runAfter time.Time) error
+	FailJob(id int64, attempt int, errMsg string) error
+	// RequeueRunning returns jobs claimed before the given time and still
+	// running to the queue; their worker is taken to have died
+	RequeueRunning(claimedBefore time.Time) (int64, error)
+}
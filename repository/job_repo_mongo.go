@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoJobRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoJobRepo(db *mongo.Client) *MongoJobRepo {
+	return &MongoJobRepo{DB: db}
+}
+
+func (r *MongoJobRepo) CreateJob(job *models.PDFJob) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	now := time.Now().UTC()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	if job.RunAfter.IsZero() {
+		job.RunAfter = now
+	}
+	job.UpdatedAt = now
+	job.Status = models.JobQueued
+
+	id, err := nextSequence(ctx, db, "pdf_job")
+	if err != nil {
+		return err
+	}
+	job.ID = id
+
+	_, err = db.Collection("pdf_job").InsertOne(ctx, job)
+	return err
+}
+
+func (r *MongoJobRepo) GetJob(id int64) (*models.PDFJob, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	var job models.PDFJob
+	err := db.Collection("pdf_job").FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimJob atomically flips the oldest due queued job to running
+func (r *MongoJobRepo) ClaimJob(now time.Time) (*models.PDFJob, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	var job models.PDFJob
+	err := db.Collection("pdf_job").FindOneAndUpdate(ctx,
+		bson.M{"status": models.JobQueued, "run_after": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{"status": models.JobRunning, "updated_at": now},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "run_after", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *MongoJobRepo) CompleteJob(id int64, attempt int, result string) error {
+	return r.update(id, attempt, bson.M{
+		"$set":   bson.M{"status": models.JobDone, "result": result, "updated_at": time.Now().UTC()},
+		"$unset": bson.M{"last_error": ""},
+	})
+}
+
+func (r *MongoJobRepo) RetryJob(id int64, attempt int, errMsg string, runAfter time.Time) error {
+	return r.update(id, attempt, bson.M{
+		"$set": bson.M{"status": models.JobQueued, "last_error": errMsg, "run_after": runAfter, "updated_at": time.Now().UTC()},
+	})
+}
+
+func (r *MongoJobRepo) FailJob(id int64, attempt int, errMsg string) error {
+	return r.update(id, attempt, bson.M{
+		"$set": bson.M{"status": models.JobFailed, "last_error": errMsg, "updated_at": time.Now().UTC()},
+	})
+}
+
+func (r *MongoJobRepo) RequeueRunning(claimedBefore time.Time) (int64, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	res, err := db.Collection("pdf_job").UpdateMany(ctx,
+		bson.M{"status": models.JobRunning, "updated_at": bson.M{"$lt": claimedBefore}},
+		bson.M{"$set": bson.M{"status": models.JobQueued, "updated_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// update settles the claim made for the given attempt; no match means the
+// claim is gone
+func (r *MongoJobRepo) update(id int64, attempt int, update bson.M) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	res, err := db.Collection("pdf_job").UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.JobRunning, "attempts": attempt}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresJobRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresJobRepo(db *sql.DB) *PostgresJobRepo {
+	return &PostgresJobRepo{DB: db}
+}
+
+const jobColumns = `id, bilty_id, options, status, attempts, max_attempts, result, last_error, run_after, created_at, updated_at`
+
+func scanJob(row rowScanner) (*models.PDFJob, error) {
+	var j models.PDFJob
+	var optionsJSON []byte
+	err := row.Scan(&j.ID, &j.BiltyID, &optionsJSON, &j.Status, &j.Attempts, &j.MaxAttempts, &j.Result, &j.LastError,
+		&j.RunAfter, &j.CreatedAt, &j.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(optionsJSON) > 0 {
+		if err := json.Unmarshal(optionsJSON, &j.Options); err != nil {
+			return nil, err
+		}
+	}
+	return &j, nil
+}
+
+func (r *PostgresJobRepo) CreateJob(job *models.PDFJob) error {
+	now := time.Now().UTC()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	if job.RunAfter.IsZero() {
+		job.RunAfter = now
+	}
+	job.UpdatedAt = now
+	job.Status = models.JobQueued
+
+	optionsJSON, err := json.Marshal(job.Options)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRow(`
+		INSERT INTO pdf_job (bilty_id, options, status, attempts, max_attempts, run_after, created_at, updated_at)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7)
+		RETURNING id
+	`, job.BiltyID, optionsJSON, job.Status, job.MaxAttempts, job.RunAfter, job.CreatedAt, job.UpdatedAt).Scan(&job.ID)
+}
+
+func (r *PostgresJobRepo) GetJob(id int64) (*models.PDFJob, error) {
+	job, err := scanJob(r.DB.QueryRow(`SELECT `+jobColumns+` FROM pdf_job WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ClaimJob uses SKIP LOCKED so several workers (or servers) never pick the same job
+func (r *PostgresJobRepo) ClaimJob(now time.Time) (*models.PDFJob, error) {
+	job, err := scanJob(r.DB.QueryRow(`
+		UPDATE pdf_job
+		SET status = $1, attempts = attempts + 1, updated_at = $2
+		WHERE id = (
+			SELECT id FROM pdf_job
+			WHERE status = $3 AND run_after <= $2
+			ORDER BY run_after, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns,
+		models.JobRunning, now, models.JobQueued))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *PostgresJobRepo) CompleteJob(id int64, attempt int, result string) error {
+	return r.settle(`
+		UPDATE pdf_job SET status = $1, result = $2, last_error = NULL, updated_at = $3
+		WHERE id = $4 AND status = $5 AND attempts = $6
+	`, models.JobDone, result, time.Now().UTC(), id, models.JobRunning, attempt)
+}
+
+func (r *PostgresJobRepo) RetryJob(id int64, attempt int, errMsg string, runAfter time.Time) error {
+	return r.settle(`
+		UPDATE pdf_job SET status = $1, last_error = $2, run_after = $3, updated_at = $4
+		WHERE id = $5 AND status = $6 AND attempts = $7
+	`, models.JobQueued, errMsg, runAfter, time.Now().UTC(), id, models.JobRunning, attempt)
+}
+
+func (r *PostgresJobRepo) FailJob(id int64, attempt int, errMsg string) error {
+	return r.settle(`
+		UPDATE pdf_job SET status = $1, last_error = $2, updated_at = $3
+		WHERE id = $4 AND status = $5 AND attempts = $6
+	`, models.JobFailed, errMsg, time.Now().UTC(), id, models.JobRunning, attempt)
+}
+
+// settle runs an update guarded by the claim; no row updated means the claim is gone
+func (r *PostgresJobRepo) settle(query string, args ...interface{}) error {
+	res, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
+
+// RequeueRunning relies on ClaimJob setting updated_at, which nothing else
+// touches while the job runs
+func (r *PostgresJobRepo) RequeueRunning(claimedBefore time.Time) (int64, error) {
+	res, err := r.DB.Exec(`
+		UPDATE pdf_job SET status = $1, updated_at = $2 WHERE status = $3 AND updated_at < $4
+	`, models.JobQueued, time.Now().UTC(), models.JobRunning, claimedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// nextSequence returns the next int64 ID for the named sequence, mirroring BIGSERIAL in Postgres
+func nextSequence(ctx context.Context, db *mongo.Database, name string) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := db.Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// RateCardRepository manages contracted tariffs
+type RateCardRepository interface {
+	// SaveRateCard creates a rate card, or updates the one with its ID
+	SaveRateCard(c *models.RateCard) error
+	GetRateCard(id int64) (*models.RateCard, error)
+	// ListRateCards lists every card, or only those of one party
+	ListRateCards(partyID *int64) ([]models.RateCard, error)
+	DeleteRateCard(id int64) error
+	// RateCardsFor returns the cards valid on day that are defaults or
+	// belong to one of the parties, for models.QuoteBilty to choose from
+	RateCardsFor(partyIDs []int64, day time.Time) ([]models.RateCard, error)
+}
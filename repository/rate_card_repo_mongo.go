@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRateCardRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoRateCardRepo(db *mongo.Client) *MongoRateCardRepo {
+	return &MongoRateCardRepo{DB: db}
+}
+
+func (r *MongoRateCardRepo) SaveRateCard(c *models.RateCard) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	now := time.Now().UTC()
+	if c.ID == 0 {
+		id, err := nextSequence(ctx, db, "rate_card")
+		if err != nil {
+			return err
+		}
+		c.ID = id
+		c.CreatedAt = now
+		_, err = db.Collection("rate_card").InsertOne(ctx, c)
+		return err
+	}
+
+	existing, err := r.GetRateCard(c.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("rate card %d not found", c.ID)
+	}
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = &now
+	_, err = db.Collection("rate_card").ReplaceOne(ctx, bson.M{"_id": c.ID}, c)
+	return err
+}
+
+func (r *MongoRateCardRepo) GetRateCard(id int64) (*models.RateCard, error) {
+	ctx := context.Background()
+	var c models.RateCard
+	err := r.DB.Database("hariomtransport").Collection("rate_card").FindOne(ctx, bson.M{"_id": id}).Decode(&c)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *MongoRateCardRepo) ListRateCards(partyID *int64) ([]models.RateCard, error) {
+	filter := bson.M{}
+	if partyID != nil {
+		filter["party_id"] = *partyID
+	}
+	return r.find(filter, options.Find().SetSort(bson.D{
+		{Key: "party_id", Value: 1}, {Key: "from_location", Value: 1}, {Key: "to_location", Value: 1},
+		{Key: "valid_from", Value: -1}, {Key: "_id", Value: 1},
+	}))
+}
+
+func (r *MongoRateCardRepo) DeleteRateCard(id int64) error {
+	ctx := context.Background()
+	res, err := r.DB.Database("hariomtransport").Collection("rate_card").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("rate card %d not found", id)
+	}
+	return nil
+}
+
+// RateCardsFor filters on party only; validity is checked by models.QuoteBilty,
+// which compares calendar days whatever time the dates were stored with
+func (r *MongoRateCardRepo) RateCardsFor(partyIDs []int64, day time.Time) ([]models.RateCard, error) {
+	parties := bson.A{bson.M{"party_id": bson.M{"$exists": false}}, bson.M{"party_id": nil}}
+	if len(partyIDs) > 0 {
+		parties = append(parties, bson.M{"party_id": bson.M{"$in": partyIDs}})
+	}
+	cards, err := r.find(bson.M{"$or": parties}, nil)
+	if err != nil {
+		return nil, err
+	}
+	valid := cards[:0]
+	for _, c := range cards {
+		if c.ValidOn(day) {
+			valid = append(valid, c)
+		}
+	}
+	return valid, nil
+}
+
+func (r *MongoRateCardRepo) find(filter bson.M, opts *options.FindOptions) ([]models.RateCard, error) {
+	ctx := context.Background()
+	cur, err := r.DB.Database("hariomtransport").Collection("rate_card").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	cards := []models.RateCard{}
+	if err := cur.All(ctx, &cards); err != nil {
+		return nil, err
+	}
+	if cards == nil {
+		cards = []models.RateCard{}
+	}
+	return cards, nil
+}
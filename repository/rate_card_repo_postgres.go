@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"github.com/lib/pq"
+)
+
+type PostgresRateCardRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresRateCardRepo(db *sql.DB) *PostgresRateCardRepo {
+	return &PostgresRateCardRepo{DB: db}
+}
+
+const rateCardColumns = `id, party_id, from_location, to_location, commodity_id, per, slabs, min_charge, valid_from, valid_to, created_at, updated_at`
+
+func scanRateCard(row rowScanner) (*models.RateCard, error) {
+	var c models.RateCard
+	var slabs []byte
+	err := row.Scan(&c.ID, &c.PartyID, &c.FromLocation, &c.ToLocation, &c.CommodityID, &c.Per, &slabs, &c.MinCharge,
+		&c.ValidFrom, &c.ValidTo, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(slabs, &c.Slabs); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresRateCardRepo) SaveRateCard(c *models.RateCard) error {
+	slabs, err := json.Marshal(c.Slabs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if c.ID == 0 {
+		c.CreatedAt = now
+		return r.DB.QueryRow(`
+			INSERT INTO rate_card (party_id, from_location, to_location, commodity_id, per, slabs, min_charge, valid_from, valid_to, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`, c.PartyID, c.FromLocation, c.ToLocation, c.CommodityID, c.Per, slabs, c.MinCharge, c.ValidFrom, c.ValidTo, c.CreatedAt).Scan(&c.ID)
+	}
+
+	c.UpdatedAt = &now
+	err = r.DB.QueryRow(`
+		UPDATE rate_card SET party_id=$1, from_location=$2, to_location=$3, commodity_id=$4, per=$5, slabs=$6,
+			min_charge=$7, valid_from=$8, valid_to=$9, updated_at=$10
+		WHERE id=$11
+		RETURNING created_at
+	`, c.PartyID, c.FromLocation, c.ToLocation, c.CommodityID, c.Per, slabs, c.MinCharge, c.ValidFrom, c.ValidTo, now, c.ID).Scan(&c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("rate card %d not found", c.ID)
+	}
+	return err
+}
+
+func (r *PostgresRateCardRepo) GetRateCard(id int64) (*models.RateCard, error) {
+	c, err := scanRateCard(r.DB.QueryRow(`SELECT `+rateCardColumns+` FROM rate_card WHERE id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *PostgresRateCardRepo) ListRateCards(partyID *int64) ([]models.RateCard, error) {
+	return queryRateCards(r.DB, `
+		SELECT `+rateCardColumns+` FROM rate_card
+		WHERE $1::BIGINT IS NULL OR party_id = $1
+		ORDER BY party_id NULLS FIRST, from_location, to_location, valid_from DESC, id
+	`, partyID)
+}
+
+func (r *PostgresRateCardRepo) DeleteRateCard(id int64) error {
+	res, err := r.DB.Exec(`DELETE FROM rate_card WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("rate card %d not found", id)
+	}
+	return nil
+}
+
+func (r *PostgresRateCardRepo) RateCardsFor(partyIDs []int64, day time.Time) ([]models.RateCard, error) {
+	return rateCardsFor(r.DB, partyIDs, day)
+}
+
+// rateCardsFor loads the candidate cards on a *sql.DB or inside a *sql.Tx
+func rateCardsFor(db queryer, partyIDs []int64, day time.Time) ([]models.RateCard, error) {
+	return queryRateCards(db, `
+		SELECT `+rateCardColumns+` FROM rate_card
+		WHERE (party_id IS NULL OR party_id = ANY($1))
+			AND valid_from <= $2::DATE AND (valid_to IS NULL OR valid_to >= $2::DATE)
+	`, pq.Array(partyIDs), day.Format("2006-01-02"))
+}
+
+func queryRateCards(db queryer, query string, args ...interface{}) ([]models.RateCard, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []models.RateCard{}
+	for rows.Next() {
+		c, err := scanRateCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, *c)
+	}
+	return cards, rows.Err()
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/hariomtransport/backend/models"
+
+// ReportRepository aggregates bilties for management reports
+type ReportRepository interface {
+	// BookingRegister totals the bilties matching q per day, branch, route or
+	// paying party. Cancelled bilties are left out unless q asks for them by status.
+	BookingRegister(q *models.BiltyQuery, groupBy string) ([]models.BookingRow, error)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoReportRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoReportRepo(db *mongo.Client) *MongoReportRepo {
+	return &MongoReportRepo{DB: db}
+}
+
+// bookingGroupKeys are the $group keys of each grouping
+var bookingGroupKeys = map[string]bson.M{
+	models.GroupByDay:    {"day": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$date"}}},
+	models.GroupByBranch: {"from": "$from_location"},
+	models.GroupByRoute:  {"from": "$from_location", "to": "$to_location"},
+	models.GroupByParty:  {"party": "$party"},
+}
+
+func (r *MongoReportRepo) BookingRegister(q *models.BiltyQuery, groupBy string) ([]models.BookingRow, error) {
+	key, ok := bookingGroupKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	filter := biltyQueryFilter(q)
+	if q.Status == "" {
+		filter["status"] = bson.M{"$ne": "cancelled"}
+	}
+	amount := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$toDecimal": bson.M{"$ifNull": bson.A{"$" + field, 0}}}}
+	}
+	freight := func(paymentType string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$payment", paymentType}},
+			bson.M{"$toDecimal": bson.M{"$ifNull": bson.A{"$to_pay", 0}}},
+			bson.M{"$toDecimal": 0},
+		}}}
+	}
+
+	cur, err := db.Collection("bilty").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$lookup", Value: bson.M{"from": "goods", "localField": "_id", "foreignField": "bilty_id", "as": "goods"}}},
+		// Bilties saved before payment types existed are to-pay
+		{{Key: "$addFields", Value: bson.M{
+			"payment": bson.M{"$ifNull": bson.A{"$payment_type", models.PaymentToPay}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"party": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$payment", models.PaymentToPay}},
+				"$consignee_company_id", "$consignor_company_id",
+			}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           key,
+			"bilties":       bson.M{"$sum": 1},
+			"packages":      bson.M{"$sum": bson.M{"$sum": "$goods.num_of_pkts"}},
+			"weight":        bson.M{"$sum": bson.M{"$sum": "$goods.weight_kg"}},
+			"to_pay":        freight(models.PaymentToPay),
+			"paid":          freight(models.PaymentPaid),
+			"tbb":           freight(models.PaymentTBB),
+			"hamali":        amount("hamali"),
+			"dd_charges":    amount("dd_charges"),
+			"fov":           amount("fov"),
+			"other_charges": amount("other_charges"),
+			"gst":           amount("gst_amount"),
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var groups []struct {
+		Key struct {
+			Day   string `bson:"day"`
+			From  string `bson:"from"`
+			To    string `bson:"to"`
+			Party *int64 `bson:"party"`
+		} `bson:"_id"`
+		Bilties      int64        `bson:"bilties"`
+		Packages     int64        `bson:"packages"`
+		Weight       float64      `bson:"weight"`
+		ToPay        models.Money `bson:"to_pay"`
+		Paid         models.Money `bson:"paid"`
+		TBB          models.Money `bson:"tbb"`
+		Hamali       models.Money `bson:"hamali"`
+		DDCharges    models.Money `bson:"dd_charges"`
+		FOV          models.Money `bson:"fov"`
+		OtherCharges models.Money `bson:"other_charges"`
+		GST          models.Money `bson:"gst"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	// Party names, fetched once for all groups
+	names := map[int64]string{}
+	if groupBy == models.GroupByParty {
+		ids := []int64{}
+		for _, g := range groups {
+			if g.Key.Party != nil {
+				ids = append(ids, *g.Key.Party)
+			}
+		}
+		cc, err := db.Collection("company").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return nil, err
+		}
+		var companies []models.Company
+		if err := cc.All(ctx, &companies); err != nil {
+			return nil, err
+		}
+		for _, c := range companies {
+			names[c.ID] = c.Name
+		}
+	}
+
+	list := []models.BookingRow{}
+	for _, g := range groups {
+		row := models.BookingRow{
+			Day:          g.Key.Day,
+			FromLocation: g.Key.From,
+			ToLocation:   g.Key.To,
+			PartyID:      g.Key.Party,
+			BookingTotals: models.BookingTotals{
+				Bilties: g.Bilties, Packages: g.Packages, WeightKG: g.Weight,
+				ToPay: g.ToPay, Paid: g.Paid, TBB: g.TBB,
+				Hamali: g.Hamali, DDCharges: g.DDCharges, FOV: g.FOV, OtherCharges: g.OtherCharges,
+				GST: g.GST,
+			},
+		}
+		if g.Key.Party != nil {
+			row.PartyName = names[*g.Key.Party]
+		}
+		row.Complete()
+		list = append(list, row)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.FromLocation != b.FromLocation {
+			return a.FromLocation < b.FromLocation
+		}
+		if a.ToLocation != b.ToLocation {
+			return a.ToLocation < b.ToLocation
+		}
+		return a.PartyName < b.PartyName
+	})
+	return list, nil
+}
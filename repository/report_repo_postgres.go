@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresReportRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresReportRepo(db *sql.DB) *PostgresReportRepo {
+	return &PostgresReportRepo{DB: db}
+}
+
+// bookingKeys are the day, from, to, party ID and party name columns each
+// grouping selects; the ones it doesn't group by are constants
+var bookingKeys = map[string]string{
+	models.GroupByDay:    `to_char(b.date, 'YYYY-MM-DD'), '', '', NULL::BIGINT, ''`,
+	models.GroupByBranch: `'', b.from_location, '', NULL::BIGINT, ''`,
+	models.GroupByRoute:  `'', b.from_location, b.to_location, NULL::BIGINT, ''`,
+	models.GroupByParty:  `'', '', '', p.id, COALESCE(p.name, '')`,
+}
+
+func (r *PostgresReportRepo) BookingRegister(q *models.BiltyQuery, groupBy string) ([]models.BookingRow, error) {
+	keys, ok := bookingKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+
+	where, args := biltyQueryWhere(q)
+	if q.Status == "" {
+		where = append(where, "b.status <> 'cancelled'")
+	}
+	cond := "TRUE"
+	if len(where) > 0 {
+		cond = strings.Join(where, " AND ")
+	}
+
+	// Goods are summed per bilty first so a bilty's charges count once
+	rows, err := r.DB.Query(`
+		SELECT `+keys+`,
+			COUNT(*), COALESCE(SUM(g.packages), 0), COALESCE(SUM(g.weight_kg), 0),
+			COALESCE(SUM(b.to_pay) FILTER (WHERE b.payment_type = 'to_pay'), 0),
+			COALESCE(SUM(b.to_pay) FILTER (WHERE b.payment_type = 'paid'), 0),
+			COALESCE(SUM(b.to_pay) FILTER (WHERE b.payment_type = 'tbb'), 0),
+			COALESCE(SUM(b.hamali), 0), COALESCE(SUM(b.dd_charges), 0),
+			COALESCE(SUM(b.fov), 0), COALESCE(SUM(b.other_charges), 0),
+			COALESCE(SUM(b.gst_amount), 0)
+		FROM bilty b
+		LEFT JOIN LATERAL (
+			SELECT SUM(num_of_pkts) AS packages, SUM(weight_kg) AS weight_kg
+			FROM goods WHERE bilty_id = b.id
+		) g ON TRUE
+		LEFT JOIN company p ON p.id = CASE WHEN b.payment_type = 'to_pay'
+			THEN b.consignee_company_id ELSE b.consignor_company_id END
+		WHERE `+cond+`
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1, 2, 3, 5
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.BookingRow{}
+	for rows.Next() {
+		var row models.BookingRow
+		t := &row.BookingTotals
+		err := rows.Scan(&row.Day, &row.FromLocation, &row.ToLocation, &row.PartyID, &row.PartyName,
+			&t.Bilties, &t.Packages, &t.WeightKG, &t.ToPay, &t.Paid, &t.TBB,
+			&t.Hamali, &t.DDCharges, &t.FOV, &t.OtherCharges, &t.GST)
+		if err != nil {
+			return nil, err
+		}
+		t.Complete()
+		list = append(list, row)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// TallyRepository keeps the Tally ledger names of parties and tracks which
+// bilties have been exported to Tally
+type TallyRepository interface {
+	// ListLedgers lists the party ledger names with their company names
+	ListLedgers() ([]models.TallyLedger, error)
+	// SaveLedger sets a party's ledger name
+	SaveLedger(l *models.TallyLedger) error
+	DeleteLedger(companyID int64) error
+	// PendingBilties lists the complete bilties matching q not yet exported
+	PendingBilties(q *models.BiltyQuery) ([]*models.Bilty, error)
+	// CreateExport records a new export batch made at the given time, marks
+	// the pending bilties matching q as part of it and returns the batch with
+	// its bilties. The batch is nil when no bilty is pending. Concurrent
+	// exports never claim the same bilty.
+	CreateExport(q *models.BiltyQuery, at time.Time) (*models.TallyExport, []*models.Bilty, error)
+	// ListExports lists the export batches, newest first
+	ListExports() ([]models.TallyExport, error)
+	// GetExport returns an export batch with its bilties, or a nil batch if
+	// there is none with the ID
+	GetExport(id int64) (*models.TallyExport, []*models.Bilty, error)
+}
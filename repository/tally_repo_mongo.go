@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoTallyRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoTallyRepo(db *mongo.Client) *MongoTallyRepo {
+	return &MongoTallyRepo{DB: db}
+}
+
+func (r *MongoTallyRepo) ListLedgers() ([]models.TallyLedger, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	cur, err := db.Collection("tally_ledger").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []models.TallyLedger{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.TallyLedger{}
+	}
+	for i := range list {
+		var c models.Company
+		if err := db.Collection("company").FindOne(ctx, bson.M{"_id": list[i].CompanyID}).Decode(&c); err == nil {
+			list[i].CompanyName = c.Name
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].CompanyName != list[j].CompanyName {
+			return list[i].CompanyName < list[j].CompanyName
+		}
+		return list[i].CompanyID < list[j].CompanyID
+	})
+	return list, nil
+}
+
+func (r *MongoTallyRepo) SaveLedger(l *models.TallyLedger) error {
+	l.UpdatedAt = time.Now().UTC()
+	_, err := r.DB.Database("hariomtransport").Collection("tally_ledger").ReplaceOne(context.Background(),
+		bson.M{"_id": l.CompanyID}, l, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoTallyRepo) DeleteLedger(companyID int64) error {
+	res, err := r.DB.Database("hariomtransport").Collection("tally_ledger").DeleteOne(context.Background(), bson.M{"_id": companyID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("no ledger set for company %d", companyID)
+	}
+	return nil
+}
+
+// pendingFilter adds the not-yet-exported conditions to a bilty filter
+func pendingFilter(q *models.BiltyQuery) bson.M {
+	filter := biltyQueryFilter(q)
+	filter["status"] = "complete"
+	filter["tally_exported_at"] = nil
+	return filter
+}
+
+func (r *MongoTallyRepo) PendingBilties(q *models.BiltyQuery) ([]*models.Bilty, error) {
+	return r.find(pendingFilter(q))
+}
+
+// CreateExport marks only documents still unexported, then reads back the
+// ones tagged with this batch, so a bilty raced by another export is
+// returned by just one of them
+func (r *MongoTallyRepo) CreateExport(q *models.BiltyQuery, at time.Time) (*models.TallyExport, []*models.Bilty, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	id, err := nextSequence(ctx, db, "tally_export")
+	if err != nil {
+		return nil, nil, err
+	}
+	batch := &models.TallyExport{
+		ID:         id,
+		DateFrom:   *q.DateFrom,
+		DateTo:     *q.DateTo,
+		ExportedAt: at.UTC().Truncate(time.Millisecond), // the precision Mongo stores
+	}
+
+	// The batch is stored before any bilty points at it, so a failure never
+	// leaves bilties tagged with a batch that doesn't exist
+	batches := db.Collection("tally_export")
+	if _, err := batches.InsertOne(ctx, batch); err != nil {
+		return nil, nil, err
+	}
+	res, err := db.Collection("bilty").UpdateMany(ctx, pendingFilter(q), bson.M{"$set": bson.M{
+		"tally_exported_at": batch.ExportedAt,
+		"tally_export_id":   batch.ID,
+	}})
+	if err != nil {
+		// Some bilties may be tagged already; the batch stays so they can
+		// still be downloaded
+		return nil, nil, err
+	}
+	if res.ModifiedCount == 0 {
+		_, err := batches.DeleteOne(ctx, bson.M{"_id": batch.ID})
+		return nil, nil, err
+	}
+	batch.Bilties = int(res.ModifiedCount)
+	if _, err := batches.UpdateOne(ctx, bson.M{"_id": batch.ID}, bson.M{"$set": bson.M{"bilties": batch.Bilties}}); err != nil {
+		return nil, nil, err
+	}
+
+	bilties, err := r.find(bson.M{"tally_export_id": batch.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, bilties, nil
+}
+
+func (r *MongoTallyRepo) ListExports() ([]models.TallyExport, error) {
+	ctx := context.Background()
+	cur, err := r.DB.Database("hariomtransport").Collection("tally_export").Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []models.TallyExport{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.TallyExport{}
+	}
+	return list, nil
+}
+
+func (r *MongoTallyRepo) GetExport(id int64) (*models.TallyExport, []*models.Bilty, error) {
+	var e models.TallyExport
+	err := r.DB.Database("hariomtransport").Collection("tally_export").FindOne(context.Background(), bson.M{"_id": id}).Decode(&e)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bilties, err := r.find(bson.M{"tally_export_id": id})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &e, bilties, nil
+}
+
+func (r *MongoTallyRepo) find(filter bson.M) ([]*models.Bilty, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	cur, err := db.Collection("bilty").Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "bilty_no", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	bilties := &MongoBiltyRepo{DB: r.DB}
+	var out []*models.Bilty
+	for cur.Next(ctx) {
+		var b models.Bilty
+		if err := cur.Decode(&b); err != nil {
+			return nil, err
+		}
+		out = append(out, bilties.populateNested(&b, ctx, db))
+	}
+	return out, cur.Err()
+}
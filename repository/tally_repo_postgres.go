@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresTallyRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresTallyRepo(db *sql.DB) *PostgresTallyRepo {
+	return &PostgresTallyRepo{DB: db}
+}
+
+func (r *PostgresTallyRepo) ListLedgers() ([]models.TallyLedger, error) {
+	rows, err := r.DB.Query(`
+		SELECT t.company_id, COALESCE(c.name, ''), t.ledger_name, t.updated_at
+		FROM tally_ledger t
+		LEFT JOIN company c ON c.id = t.company_id
+		ORDER BY c.name, t.company_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.TallyLedger{}
+	for rows.Next() {
+		var l models.TallyLedger
+		if err := rows.Scan(&l.CompanyID, &l.CompanyName, &l.LedgerName, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresTallyRepo) SaveLedger(l *models.TallyLedger) error {
+	l.UpdatedAt = time.Now().UTC()
+	_, err := r.DB.Exec(`
+		INSERT INTO tally_ledger (company_id, ledger_name, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (company_id) DO UPDATE SET ledger_name = EXCLUDED.ledger_name, updated_at = EXCLUDED.updated_at
+	`, l.CompanyID, l.LedgerName, l.UpdatedAt)
+	return err
+}
+
+func (r *PostgresTallyRepo) DeleteLedger(companyID int64) error {
+	res, err := r.DB.Exec(`DELETE FROM tally_ledger WHERE company_id=$1`, companyID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no ledger set for company %d", companyID)
+	}
+	return nil
+}
+
+// pendingWhere adds the not-yet-exported conditions to a bilty query
+func pendingWhere(q *models.BiltyQuery) ([]string, []interface{}) {
+	where, args := biltyQueryWhere(q)
+	where = append(where, "b.status = 'complete'", "b.tally_exported_at IS NULL")
+	return where, args
+}
+
+func (r *PostgresTallyRepo) PendingBilties(q *models.BiltyQuery) ([]*models.Bilty, error) {
+	where, args := pendingWhere(q)
+	return (&PostgresBiltyRepo{DB: r.DB}).selectBilties(r.DB, where, args, "b.date, b.bilty_no")
+}
+
+func (r *PostgresTallyRepo) CreateExport(q *models.BiltyQuery, at time.Time) (*models.TallyExport, []*models.Bilty, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	batch := &models.TallyExport{DateFrom: *q.DateFrom, DateTo: *q.DateTo, ExportedAt: at}
+	err = tx.QueryRow(`
+		INSERT INTO tally_export (date_from, date_to, exported_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, batch.DateFrom, batch.DateTo, batch.ExportedAt).Scan(&batch.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The UPDATE locks the rows it marks; a concurrent export waits, then
+	// finds them exported and skips them
+	where, args := pendingWhere(q)
+	args = append(args, at, batch.ID)
+	res, err := tx.Exec(fmt.Sprintf(`
+		UPDATE bilty b SET tally_exported_at = $%d, tally_export_id = $%d
+		WHERE %s
+	`, len(args)-1, len(args), strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n == 0 {
+		return nil, nil, nil
+	}
+	batch.Bilties = int(n)
+	if _, err := tx.Exec(`UPDATE tally_export SET bilties=$1 WHERE id=$2`, batch.Bilties, batch.ID); err != nil {
+		return nil, nil, err
+	}
+
+	bilties, err := (&PostgresBiltyRepo{DB: r.DB}).selectBilties(tx, []string{"b.tally_export_id = $1"}, []interface{}{batch.ID}, "b.date, b.bilty_no")
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, bilties, tx.Commit()
+}
+
+func (r *PostgresTallyRepo) ListExports() ([]models.TallyExport, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, date_from, date_to, exported_at, bilties
+		FROM tally_export
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.TallyExport{}
+	for rows.Next() {
+		var e models.TallyExport
+		if err := rows.Scan(&e.ID, &e.DateFrom, &e.DateTo, &e.ExportedAt, &e.Bilties); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresTallyRepo) GetExport(id int64) (*models.TallyExport, []*models.Bilty, error) {
+	var e models.TallyExport
+	err := r.DB.QueryRow(`
+		SELECT id, date_from, date_to, exported_at, bilties
+		FROM tally_export WHERE id=$1
+	`, id).Scan(&e.ID, &e.DateFrom, &e.DateTo, &e.ExportedAt, &e.Bilties)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bilties, err := (&PostgresBiltyRepo{DB: r.DB}).selectBilties(r.DB, []string{"b.tally_export_id = $1"}, []interface{}{id}, "b.date, b.bilty_no")
+	if err != nil {
+		return nil, nil, err
+	}
+	return &e, bilties, nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "github.com/hariomtransport/backend/models"
+
+// TemplateRepository stores versioned print templates
+type TemplateRepository interface {
+	CreateTemplate(t *models.BiltyTemplate) error
+	GetTemplates(name string) ([]*models.BiltyTemplate, error)
+	GetTemplateByID(id int64) (*models.BiltyTemplate, error)
+	GetActiveTemplate(name string) (*models.BiltyTemplate, error)
+	ActivateTemplate(id int64) error
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoTemplateRepo struct {
+	DB *mongo.Client
+}
+
+func NewMongoTemplateRepo(db *mongo.Client) *MongoTemplateRepo {
+	return &MongoTemplateRepo{DB: db}
+}
+
+func (r *MongoTemplateRepo) CreateTemplate(t *models.BiltyTemplate) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	t.IsActive = false
+
+	id, err := nextSequence(ctx, db, "bilty_template")
+	if err != nil {
+		return err
+	}
+	t.ID = id
+
+	var latest models.BiltyTemplate
+	err = db.Collection("bilty_template").FindOne(ctx,
+		bson.M{"name": t.Name},
+		options.FindOne().SetSort(bson.M{"version": -1}),
+	).Decode(&latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	t.Version = latest.Version + 1
+
+	_, err = db.Collection("bilty_template").InsertOne(ctx, t)
+	return err
+}
+
+func (r *MongoTemplateRepo) GetTemplates(name string) ([]*models.BiltyTemplate, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	cur, err := db.Collection("bilty_template").Find(ctx,
+		bson.M{"name": name},
+		options.Find().SetSort(bson.M{"version": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var list []*models.BiltyTemplate
+	for cur.Next(ctx) {
+		var t models.BiltyTemplate
+		if err := cur.Decode(&t); err != nil {
+			return nil, err
+		}
+		list = append(list, &t)
+	}
+	return list, cur.Err()
+}
+
+func (r *MongoTemplateRepo) GetTemplateByID(id int64) (*models.BiltyTemplate, error) {
+	return r.findOne(bson.M{"_id": id})
+}
+
+func (r *MongoTemplateRepo) GetActiveTemplate(name string) (*models.BiltyTemplate, error) {
+	return r.findOne(bson.M{"name": name, "is_active": true})
+}
+
+func (r *MongoTemplateRepo) ActivateTemplate(id int64) error {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	t, err := r.GetTemplateByID(id)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	_, err = db.Collection("bilty_template").UpdateMany(ctx,
+		bson.M{"name": t.Name, "is_active": true},
+		bson.M{"$set": bson.M{"is_active": false}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("bilty_template").UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"is_active": true, "activated_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+func (r *MongoTemplateRepo) findOne(filter bson.M) (*models.BiltyTemplate, error) {
+	ctx := context.Background()
+	db := r.DB.Database("hariomtransport")
+
+	var t models.BiltyTemplate
+	err := db.Collection("bilty_template").FindOne(ctx, filter).Decode(&t)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
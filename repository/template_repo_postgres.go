@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+type PostgresTemplateRepo struct {
+	DB *sql.DB
+}
+
+func NewPostgresTemplateRepo(db *sql.DB) *PostgresTemplateRepo {
+	return &PostgresTemplateRepo{DB: db}
+}
+
+// CreateTemplate stores a new inactive version, numbering it after the latest one with the same name
+func (r *PostgresTemplateRepo) CreateTemplate(t *models.BiltyTemplate) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	t.IsActive = false
+
+	return r.DB.QueryRow(`
+		INSERT INTO bilty_template (name, version, content, is_active, created_by, created_at)
+		VALUES ($1, (SELECT COALESCE(MAX(version), 0) + 1 FROM bilty_template WHERE name = $1), $2, false, $3, $4)
+		RETURNING id, version
+	`, t.Name, t.Content, t.CreatedBy, t.CreatedAt).Scan(&t.ID, &t.Version)
+}
+
+// GetTemplates lists all versions of a template, newest first
+func (r *PostgresTemplateRepo) GetTemplates(name string) ([]*models.BiltyTemplate, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, name, version, content, is_active, created_by, created_at, activated_at
+		FROM bilty_template
+		WHERE name = $1
+		ORDER BY version DESC
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.BiltyTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresTemplateRepo) GetTemplateByID(id int64) (*models.BiltyTemplate, error) {
+	t, err := scanTemplate(r.DB.QueryRow(`
+		SELECT id, name, version, content, is_active, created_by, created_at, activated_at
+		FROM bilty_template
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *PostgresTemplateRepo) GetActiveTemplate(name string) (*models.BiltyTemplate, error) {
+	t, err := scanTemplate(r.DB.QueryRow(`
+		SELECT id, name, version, content, is_active, created_by, created_at, activated_at
+		FROM bilty_template
+		WHERE name = $1 AND is_active
+	`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// ActivateTemplate makes the given version the only active one for its name
+func (r *PostgresTemplateRepo) ActivateTemplate(id int64) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name string
+	if err := tx.QueryRow(`SELECT name FROM bilty_template WHERE id = $1`, id).Scan(&name); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE bilty_template SET is_active = false WHERE name = $1 AND is_active`, name); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE bilty_template SET is_active = true, activated_at = $1 WHERE id = $2
+	`, time.Now().UTC(), id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTemplate(row rowScanner) (*models.BiltyTemplate, error) {
+	var t models.BiltyTemplate
+	err := row.Scan(&t.ID, &t.Name, &t.Version, &t.Content, &t.IsActive, &t.CreatedBy, &t.CreatedAt, &t.ActivatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
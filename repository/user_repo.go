@@ -6,4 +6,6 @@ import "github.com/hariomtransport/backend/models"
 type UserRepository interface {
 	CreateUser(user *models.AppUser) error
 	GetUserByEmail(email string) (*models.AppUser, error)
+	// CountUsers returns the number of user accounts
+	CountUsers() (int64, error)
 }
@@ -44,3 +44,7 @@ func (r *MongoUserRepo) GetUserByEmail(email string) (*models.AppUser, error) {
 
 	return user, nil
 }
+
+func (r *MongoUserRepo) CountUsers() (int64, error) {
+	return r.DB.Database("hariomtransport").Collection("app_user").CountDocuments(context.Background(), bson.M{})
+}
@@ -45,12 +45,11 @@ func (r *PostgresUserRepo) CreateUser(user *models.AppUser) error {
 	}
 
 	// 4️⃣ Insert into DB
-	_, err = r.DB.Exec(`
+	return r.DB.QueryRow(`
 		INSERT INTO app_user (name, email, password, role, created_at)
 		VALUES ($1, $2, $3, $4, $5)
-	`, user.Name, user.Email, user.Password, user.Role, user.CreatedAt)
-
-	return err
+		RETURNING id
+	`, user.Name, user.Email, user.Password, user.Role, user.CreatedAt).Scan(&user.ID)
 }
 
 // GetUserByEmail fetches user by email
@@ -71,3 +70,10 @@ func (r *PostgresUserRepo) GetUserByEmail(email string) (*models.AppUser, error)
 
 	return user, nil
 }
+
+// CountUsers returns the number of user accounts
+func (r *PostgresUserRepo) CountUsers() (int64, error) {
+	var n int64
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM app_user`).Scan(&n)
+	return n, err
+}
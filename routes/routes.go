@@ -2,11 +2,14 @@ package routes
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/hariomtransport/backend/handlers"
+	"github.com/hariomtransport/backend/storage"
+	"github.com/hariomtransport/backend/utils"
 )
 
-// CORS middleware
+// CORS middleware; it also tags the request with the client's address for audit events
 func withCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*") // Replace * with your domain in production
@@ -19,7 +22,7 @@ func withCORS(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(utils.WithRequest(r.Context(), r)))
 	})
 }
 
@@ -28,29 +31,54 @@ func SetupRoutes(
 	biltyHandler *handlers.BiltyHandler,
 	initialHandler *handlers.InitialHandler,
 	pdfHandler *handlers.PDFHandler,
+	templateHandler *handlers.TemplateHandler,
+	jobHandler *handlers.JobHandler,
+	verifyHandler *handlers.VerifyHandler,
+	auditHandler *handlers.AuditHandler,
+	commodityHandler *handlers.CommodityHandler,
+	rateCardHandler *handlers.RateCardHandler,
+	stationHandler *handlers.StationHandler,
+	reportHandler *handlers.ReportHandler,
+	tallyHandler *handlers.TallyHandler,
+	files http.Handler,
+	tokens *utils.TokenManager,
 ) {
+	adminOnly := handlers.RequireRole(tokens, "admin")
+	signedIn := handlers.RequireRole(tokens)
+	withUser := handlers.OptionalAuth(tokens)
+
 	// User routes
-	http.Handle("/signup", withCORS(http.HandlerFunc(handlers.RecoverWrapper(userHandler.Signup))))
+	http.Handle("/signup", withCORS(http.HandlerFunc(handlers.RecoverWrapper(withUser(userHandler.Signup)))))
 	http.Handle("/login", withCORS(http.HandlerFunc(handlers.RecoverWrapper(userHandler.Login))))
-	http.Handle("/bilty/pdf", withCORS(http.HandlerFunc(handlers.RecoverWrapper(pdfHandler.BiltyPDF))))
+	http.Handle("/bilty/pdf", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(pdfHandler.BiltyPDF)))))
+	http.Handle("/bilty/pdf/download", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(pdfHandler.DownloadPDF)))))
+	http.Handle("/bilty/pdf/profiles", withCORS(http.HandlerFunc(handlers.RecoverWrapper(pdfHandler.ListProfiles))))
+	http.Handle("/bilty/export", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(biltyHandler.ExportBilties)))))
+	http.Handle("/bilty/pdf/bulk", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(pdfHandler.BulkPDF)))))
 
 	// Bilty routes
-	http.Handle("/bilty", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+	http.Handle("/bilty", withCORS(http.HandlerFunc(handlers.RecoverWrapper(withUser(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			biltyHandler.CreateBilty(w, r)
+			signedIn(biltyHandler.CreateBilty)(w, r)
 		case http.MethodGet:
 			biltyHandler.GetAllBilty(w, r)
 		case http.MethodDelete:
-			biltyHandler.DeleteBilty(w, r)
+			signedIn(biltyHandler.DeleteBilty)(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
-	}))))
+	})))))
 
-	// Get bilty by ID
+	// Get bilty by ID, or its history at /bilty/{id}/history (signed in users only)
 	http.Handle("/bilty/", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
 		id := r.URL.Path[len("/bilty/"):]
+		if biltyID, ok := strings.CutSuffix(id, "/history"); ok && r.Method == http.MethodGet {
+			signedIn(func(w http.ResponseWriter, r *http.Request) {
+				biltyHandler.GetBiltyHistory(w, r, biltyID)
+			})(w, r)
+			return
+		}
 		if id != "" {
 			biltyHandler.GetBiltyByID(w, r, id)
 			return
@@ -58,15 +86,154 @@ func SetupRoutes(
 		w.WriteHeader(http.StatusNotFound)
 	}))))
 
-	// Initial setup routes
-	http.Handle("/initial", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+	// Initial setup routes: anyone can read it, admins save it
+	http.Handle("/initial", withCORS(http.HandlerFunc(handlers.RecoverWrapper(withUser(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			initialHandler.SaveInitial(w, r)
+			adminOnly(initialHandler.SaveInitial)(w, r)
 		case http.MethodGet:
 			initialHandler.GetInitial(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
+	})))))
+
+	// Background job status
+	http.Handle("/jobs/", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/jobs/"):]
+		if id != "" && r.Method == http.MethodGet {
+			jobHandler.GetJob(w, r, id)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})))))
+
+	// Public bilty verification (linked from the QR code on prints)
+	http.Handle("/verify/", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Path[len("/verify/"):]
+		if token != "" && r.Method == http.MethodGet {
+			verifyHandler.VerifyBilty(w, r, token)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))))
+
+	// Stored files, only when the storage backend serves them itself
+	if files != nil {
+		http.Handle(storage.LocalFilesPath, withCORS(http.StripPrefix(storage.LocalFilesPath, files)))
+	}
+
+	// Template routes (admin only)
+	http.Handle("/templates", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			templateHandler.UploadTemplate(w, r)
+		case http.MethodGet:
+			templateHandler.ListTemplates(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})))))
+	http.Handle("/templates/activate", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(templateHandler.ActivateTemplate)))))
+	http.Handle("/templates/rollback", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(templateHandler.RollbackTemplate)))))
+	http.Handle("/templates/preview", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(templateHandler.PreviewTemplate)))))
+
+	// Commodity catalogue: anyone can search, admins maintain it
+	http.Handle("/commodities", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			commodityHandler.SearchCommodities(w, r)
+		case http.MethodPost:
+			adminOnly(commodityHandler.SaveCommodity)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))))
+	http.Handle("/commodities/", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/commodities/"):]
+		if id != "" && r.Method == http.MethodGet {
+			commodityHandler.GetCommodity(w, r, id)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))))
+
+	// Rate cards: admins maintain them, clerks get quotes
+	http.Handle("/rate-cards", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rateCardHandler.ListRateCards(w, r)
+		case http.MethodPost:
+			rateCardHandler.SaveRateCard(w, r)
+		case http.MethodDelete:
+			rateCardHandler.DeleteRateCard(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})))))
+	http.Handle("/rate-cards/quote", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		rateCardHandler.QuoteBilty(w, r)
+	})))))
+
+	// Station master autocomplete
+	http.Handle("/stations", withCORS(http.HandlerFunc(handlers.RecoverWrapper(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		stationHandler.SearchStations(w, r)
 	}))))
+
+	// Reports (signed in users only)
+	http.Handle("/reports/commodities", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(commodityHandler.CommodityReport)))))
+	http.Handle("/reports/bookings", withCORS(http.HandlerFunc(handlers.RecoverWrapper(signedIn(reportHandler.BookingRegister)))))
+
+	// Tally accounting export (admin only)
+	http.Handle("/tally/export", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tallyHandler.ExportVouchers(w, r)
+	})))))
+	http.Handle("/tally/exports", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tallyHandler.ListExports(w, r)
+	})))))
+	http.Handle("/tally/exports/", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/tally/exports/"):]
+		if id != "" && r.Method == http.MethodGet {
+			tallyHandler.DownloadExport(w, r, id)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})))))
+	http.Handle("/tally/ledgers", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			tallyHandler.ListLedgers(w, r)
+		case http.MethodPost:
+			tallyHandler.SaveLedger(w, r)
+		case http.MethodDelete:
+			tallyHandler.DeleteLedger(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})))))
+
+	// Audit log (admin only)
+	http.Handle("/audit", withCORS(http.HandlerFunc(handlers.RecoverWrapper(adminOnly(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		auditHandler.ListAuditEvents(w, r)
+	})))))
 }
@@ -0,0 +1,77 @@
+package stations
+
+import (
+	"strings"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// Canonical returns the station name for a typed location, or the location
+// trimmed when it isn't a known station
+func (d *Directory) Canonical(location string) string {
+	if s := d.Lookup(location); s != nil {
+		return s.Name
+	}
+	return strings.TrimSpace(location)
+}
+
+// NormalizeBilty rewrites the bilty's route and party addresses to canonical
+// station names and sets its GST place of supply
+func (d *Directory) NormalizeBilty(b *models.Bilty) {
+	b.FromLocation = d.Canonical(b.FromLocation)
+	b.ToLocation = d.Canonical(b.ToLocation)
+	d.normalizeAddress(b.ConsignorAddressSnap)
+	d.normalizeAddress(b.ConsigneeAddressSnap)
+
+	if state, ok := d.PlaceOfSupply(b); ok {
+		b.PlaceOfSupply = &state.Name
+		b.PlaceOfSupplyCode = &state.Code
+	} else {
+		b.PlaceOfSupply, b.PlaceOfSupplyCode = nil, nil
+	}
+}
+
+// normalizeAddress fills the city and state from the pincode when they are
+// missing, and spells known cities and states canonically
+func (d *Directory) normalizeAddress(a *models.BiltyAddress) {
+	if a == nil {
+		return
+	}
+	station := d.Lookup(a.City)
+	if station == nil && strings.TrimSpace(a.City) == "" {
+		station = d.ByPincode(a.Pincode)
+	}
+	if station != nil {
+		a.City = station.Name
+		if strings.TrimSpace(a.State) == "" {
+			a.State = station.State
+		}
+	}
+	if state, ok := d.StateByName(a.State); ok {
+		a.State = state.Name
+	}
+}
+
+// PlaceOfSupply decides the state a transport bilty is taxed in. For a
+// registered recipient it is the state of their GSTIN; otherwise it is where
+// the goods were handed over, the origin station. The recipient is the
+// consignee on a to-pay bilty and the consignor on a paid or TBB one.
+func (d *Directory) PlaceOfSupply(b *models.Bilty) (State, bool) {
+	payer := b.ConsignorCompany
+	if b.ConsigneePays() {
+		payer = b.ConsigneeCompany
+	}
+	if payer != nil && payer.GSTIN != nil && len(*payer.GSTIN) >= 2 {
+		if state, ok := d.StateByCode((*payer.GSTIN)[:2]); ok {
+			return state, true
+		}
+	}
+
+	if s := d.Lookup(b.FromLocation); s != nil {
+		return State{Code: s.StateCode, Name: s.State}, true
+	}
+	if a := b.ConsignorAddressSnap; a != nil {
+		return d.StateByName(a.State)
+	}
+	return State{}, false
+}
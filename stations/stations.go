@@ -0,0 +1,199 @@
+// Package stations is the master list of booking stations: canonical names,
+// the spellings people type for them, and the district, state and pincodes
+// each covers. The list ships with the binary and can be replaced by a file
+// in the same JSON format.
+package stations
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed stations.json
+var bundled []byte
+
+// State is an Indian state or union territory with its GST state code
+type State struct {
+	Code string `json:"code"` // two digits, the first two of a GSTIN
+	Name string `json:"name"`
+}
+
+// PincodeRange is an inclusive range of six-digit pincodes
+type PincodeRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Station is a canonical booking location
+type Station struct {
+	Name      string         `json:"name"`
+	District  string         `json:"district"`
+	State     string         `json:"state"`
+	StateCode string         `json:"state_code"`
+	Pincodes  []PincodeRange `json:"pincodes,omitempty"`
+	Aliases   []string       `json:"aliases,omitempty"`
+}
+
+// Directory looks stations up by name, alias or pincode
+type Directory struct {
+	stations []Station
+	byKey    map[string]int // normalized name or alias -> index
+	states   map[string]State
+}
+
+type dataset struct {
+	States   []State   `json:"states"`
+	Stations []Station `json:"stations"`
+}
+
+// Bundled returns the directory shipped with the binary
+func Bundled() (*Directory, error) {
+	return Parse(bundled)
+}
+
+// Load reads a directory from a JSON file, or returns the bundled one when
+// path is empty
+func Load(path string) (*Directory, error) {
+	if path == "" {
+		return Bundled()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse builds a directory from JSON. Every station must name a known state
+// code, and no two stations may share a name or alias.
+func Parse(data []byte) (*Directory, error) {
+	var ds dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("invalid station data: %w", err)
+	}
+
+	d := &Directory{byKey: map[string]int{}, states: map[string]State{}}
+	for _, s := range ds.States {
+		d.states[s.Code] = s
+	}
+	for i, s := range ds.Stations {
+		state, ok := d.states[s.StateCode]
+		if !ok {
+			return nil, fmt.Errorf("station %q has unknown state code %q", s.Name, s.StateCode)
+		}
+		s.State = state.Name
+		for _, name := range append([]string{s.Name}, s.Aliases...) {
+			k := key(name)
+			if other, dup := d.byKey[k]; dup && other != i {
+				return nil, fmt.Errorf("%q names both %q and %q", name, ds.Stations[other].Name, s.Name)
+			}
+			d.byKey[k] = i
+		}
+		d.stations = append(d.stations, s)
+	}
+	return d, nil
+}
+
+// key folds case, spaces and punctuation so "NEW DELHI", "New-Delhi" and
+// "newdelhi" are the same station
+func key(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Lookup finds a station by its name or an alias, or returns nil
+func (d *Directory) Lookup(name string) *Station {
+	if i, ok := d.byKey[key(name)]; ok {
+		s := d.stations[i]
+		return &s
+	}
+	return nil
+}
+
+// ByPincode finds the station whose pincode ranges cover pin, or returns nil
+func (d *Directory) ByPincode(pin string) *Station {
+	n, err := strconv.Atoi(strings.TrimSpace(pin))
+	if err != nil {
+		return nil
+	}
+	for _, s := range d.stations {
+		for _, r := range s.Pincodes {
+			if n >= r.From && n <= r.To {
+				return &s
+			}
+		}
+	}
+	return nil
+}
+
+// StateByCode returns the state with the GST state code, e.g. "27"
+func (d *Directory) StateByCode(code string) (State, bool) {
+	s, ok := d.states[code]
+	return s, ok
+}
+
+// StateByName finds a state by name, ignoring case and spacing
+func (d *Directory) StateByName(name string) (State, bool) {
+	k := key(name)
+	for _, s := range d.states {
+		if key(s.Name) == k {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// Search backs the location autocomplete: stations whose name or an alias
+// starts with term come first, then those containing it. A six-digit term
+// is looked up as a pincode.
+func (d *Directory) Search(term string, limit int) []Station {
+	out := []Station{}
+	if len(strings.TrimSpace(term)) == 6 {
+		if s := d.ByPincode(term); s != nil {
+			return append(out, *s)
+		}
+	}
+
+	k := key(term)
+	type match struct {
+		station Station
+		prefix  bool
+	}
+	var matches []match
+	for _, s := range d.stations {
+		prefix, contains := false, false
+		for _, name := range append([]string{s.Name}, s.Aliases...) {
+			nk := key(name)
+			prefix = prefix || strings.HasPrefix(nk, k)
+			contains = contains || strings.Contains(nk, k)
+		}
+		if contains {
+			matches = append(matches, match{s, prefix})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].prefix != matches[j].prefix {
+			return matches[i].prefix
+		}
+		return matches[i].station.Name < matches[j].station.Name
+	})
+
+	for _, m := range matches {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, m.station)
+	}
+	return out
+}
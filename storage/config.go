@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hariomtransport/backend/config"
+)
+
+// LocalFilesPath is where the local backend serves its files
+const LocalFilesPath = "/files/"
+
+// FromConfig builds the storage backend selected in the config
+func FromConfig(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageBackend {
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:        cfg.StorageEndpoint,
+			Region:          cfg.StorageRegion,
+			Bucket:          cfg.StorageBucket,
+			AccessKeyID:     cfg.StorageAccessKeyID,
+			SecretAccessKey: cfg.StorageSecretAccessKey,
+			PathStyle:       cfg.StoragePathStyle,
+		})
+	case "local":
+		baseURL := ""
+		if cfg.PublicBaseURL != "" {
+			baseURL = strings.TrimRight(cfg.PublicBaseURL, "/") + strings.TrimSuffix(LocalFilesPath, "/")
+		}
+		return NewLocal(cfg.StorageDir, baseURL, cfg.AuthSecret)
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unsupported STORAGE_BACKEND %q", cfg.StorageBackend)
+	}
+}
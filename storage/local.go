@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local stores objects as files under Dir. ServeHTTP serves them at BaseURL,
+// e.g. "https://api.example.com/files", through signed links only.
+type Local struct {
+	Dir     string
+	BaseURL string
+	secret  []byte
+}
+
+// NewLocal creates a local store; secret signs download links and falls back
+// to a random one, so links die with the process
+func NewLocal(dir, baseURL, secret string) (*Local, error) {
+	if dir == "" {
+		dir = "./pdfs"
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	return &Local{Dir: dir, BaseURL: strings.TrimRight(baseURL, "/"), secret: key}, nil
+}
+
+// path maps key to a file inside Dir, rejecting keys that escape it
+func (s *Local) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *Local) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial object
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (s *Local) Get(ctx context.Context, key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Local) Exists(ctx context.Context, key string) (bool, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if s.BaseURL == "" {
+		return "", errors.New("local storage has no base URL to link to")
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.BaseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+func (s *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Local) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.Dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+// ServeHTTP serves objects for links made by SignedURL. It must be mounted
+// with the base URL's path stripped, e.g. http.StripPrefix("/files/", s).
+func (s *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Path
+	expires := r.URL.Query().Get("expires")
+	sig := r.URL.Query().Get("signature")
+
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp || !hmac.Equal([]byte(sig), []byte(s.sign(key, expires))) {
+		http.Error(w, "Link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	p, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, p)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory keeps objects in process memory, for tests and throwaway runs
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data     []byte
+	modified time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{objects: make(map[string]memoryObject)}
+}
+
+func (s *Memory) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{data: append([]byte(nil), data...), modified: time.Now()}
+	return nil
+}
+
+func (s *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), obj.data...), nil
+}
+
+func (s *Memory) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *Memory) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+func (s *Memory) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return fmt.Sprintf("memory://%s?expires=%d", key, time.Now().Add(expiry).Unix()), nil
+}
+
+func (s *Memory) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var objects []ObjectInfo
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: int64(len(obj.data)), LastModified: obj.modified})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"time"
+)
+
+// Storage keeps generated files such as bilty PDFs. Objects are private and
+// only reachable through the API or a SignedURL.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Open streams an object; the caller must close it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// SignedURL returns a link to the object that stops working after expiry
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ErrNotFound is returned when the requested object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// KeyFromLocation returns the key of an object from a stored location, which
+// is either the key itself or a public URL saved before storage went private.
+// Objects behind those URLs were stored flat, under their file name.
+func KeyFromLocation(location string) string {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return location
+	}
+	return path.Base(u.Path)
+}
@@ -0,0 +1,195 @@
+// Package tally turns bilties into Tally vouchers and writes them as a Tally
+// XML import file.
+package tally
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// Ledgers names the Tally ledgers the vouchers post to
+type Ledgers struct {
+	Company string // Tally company to import into; empty imports into the open one
+	Freight string // freight income
+	Charges string // hamali, door delivery, FOV and other charges
+	CGST    string
+	SGST    string
+	IGST    string
+	Cash    string // receipts, and sales to parties without a company
+}
+
+// Voucher is a Tally accounting voucher
+type Voucher struct {
+	RemoteID    string  `xml:"REMOTEID,attr"` // stable per bilty, so Tally knows a re-import
+	Type        string  `xml:"VCHTYPE,attr"`
+	Action      string  `xml:"ACTION,attr"`
+	Date        string  `xml:"DATE"` // YYYYMMDD
+	TypeName    string  `xml:"VOUCHERTYPENAME"`
+	Number      string  `xml:"VOUCHERNUMBER"`
+	Reference   string  `xml:"REFERENCE,omitempty"`
+	PartyLedger string  `xml:"PARTYLEDGERNAME"`
+	Narration   string  `xml:"NARRATION,omitempty"`
+	View        string  `xml:"PERSISTEDVIEW"`
+	Entries     []Entry `xml:"ALLLEDGERENTRIES.LIST"`
+}
+
+// Entry is one ledger line of a voucher. Tally writes debits as negative
+// amounts marked deemed positive.
+type Entry struct {
+	Ledger         string `xml:"LEDGERNAME"`
+	DeemedPositive string `xml:"ISDEEMEDPOSITIVE"`
+	Amount         string `xml:"AMOUNT"`
+}
+
+func debit(ledger string, m models.Money) Entry {
+	return Entry{Ledger: ledger, DeemedPositive: "Yes", Amount: (-m).String()}
+}
+
+func credit(ledger string, m models.Money) Entry {
+	return Entry{Ledger: ledger, DeemedPositive: "No", Amount: m.String()}
+}
+
+// Vouchers books each bilty as a sales voucher against the party paying its
+// freight, plus a receipt voucher for paid bilties, whose freight is collected
+// in cash at booking. homeState is the GST state code of the transporter:
+// GST is split into CGST and SGST when the place of supply is the same state
+// (or unknown), and posted as IGST otherwise. partyLedger names the ledger of
+// a party; parties without one are booked to cash.
+func Vouchers(bilties []*models.Bilty, l Ledgers, homeState string, partyLedger func(*models.Company) string) []Voucher {
+	var out []Voucher
+	for _, b := range bilties {
+		payer := b.ConsignorCompany
+		if b.ConsigneePays() {
+			payer = b.ConsigneeCompany
+		}
+		party := ""
+		if payer != nil {
+			party = partyLedger(payer)
+		}
+		if party == "" {
+			party = l.Cash
+		}
+
+		sale, total := salesVoucher(b, l, homeState, party)
+		if total == 0 {
+			continue
+		}
+		out = append(out, sale)
+		if b.PaymentType == models.PaymentPaid && party != l.Cash {
+			out = append(out, receiptVoucher(sale, l.Cash, total))
+		}
+	}
+	return out
+}
+
+// salesVoucher books the bilty's total, split into freight and charges, and
+// the GST over it; the total is zero when there is nothing to book
+func salesVoucher(b *models.Bilty, l Ledgers, homeState, party string) (Voucher, models.Money) {
+	var credits []Entry
+	var total models.Money
+	add := func(ledger string, m models.Money) {
+		if m != 0 {
+			credits = append(credits, credit(ledger, m))
+			total += m
+		}
+	}
+
+	add(l.Freight, b.Freight())
+	add(l.Charges, b.Charges())
+	gst := models.MoneyOrZero(b.GSTAmount)
+	if b.PlaceOfSupplyCode != nil && homeState != "" && *b.PlaceOfSupplyCode != homeState {
+		add(l.IGST, gst)
+	} else {
+		half := gst / 2
+		add(l.CGST, half)
+		add(l.SGST, gst-half)
+	}
+
+	return Voucher{
+		RemoteID:    fmt.Sprintf("bilty-%d-sales", b.ID),
+		Type:        "Sales",
+		Action:      "Create",
+		Date:        b.Date.Format("20060102"),
+		TypeName:    "Sales",
+		Number:      fmt.Sprint(b.BiltyNo),
+		Reference:   fmt.Sprintf("Bilty %d", b.BiltyNo),
+		PartyLedger: party,
+		Narration:   narration(b),
+		View:        "Accounting Voucher View",
+		Entries:     append([]Entry{debit(party, total)}, credits...),
+	}, total
+}
+
+// receiptVoucher records the party paying a sale in cash
+func receiptVoucher(sale Voucher, cash string, total models.Money) Voucher {
+	return Voucher{
+		RemoteID:    strings.TrimSuffix(sale.RemoteID, "-sales") + "-receipt",
+		Type:        "Receipt",
+		Action:      "Create",
+		Date:        sale.Date,
+		TypeName:    "Receipt",
+		Number:      sale.Number,
+		Reference:   sale.Reference,
+		PartyLedger: sale.PartyLedger,
+		Narration:   "Freight paid at booking, " + sale.Narration,
+		View:        "Accounting Voucher View",
+		Entries:     []Entry{debit(cash, total), credit(sale.PartyLedger, total)},
+	}
+}
+
+// narration describes the consignment, e.g. "Bilty 1001, Patna to Delhi, inv INV-7"
+func narration(b *models.Bilty) string {
+	parts := []string{fmt.Sprintf("Bilty %d", b.BiltyNo)}
+	if b.FromLocation != "" || b.ToLocation != "" {
+		parts = append(parts, strings.TrimSpace(b.FromLocation+" to "+b.ToLocation))
+	}
+	if b.InvNo != nil && *b.InvNo != "" {
+		parts = append(parts, "inv "+*b.InvNo)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type envelope struct {
+	XMLName xml.Name `xml:"ENVELOPE"`
+	Request string   `xml:"HEADER>TALLYREQUEST"`
+	Import  struct {
+		ReportName string    `xml:"REQUESTDESC>REPORTNAME"`
+		Static     *static   `xml:"REQUESTDESC>STATICVARIABLES,omitempty"`
+		Messages   []message `xml:"REQUESTDATA>TALLYMESSAGE"`
+	} `xml:"BODY>IMPORTDATA"`
+}
+
+type static struct {
+	Company string `xml:"SVCURRENTCOMPANY"`
+}
+
+type message struct {
+	Voucher Voucher `xml:"VOUCHER"`
+}
+
+// Write writes the vouchers as a Tally "Import Data" request
+func Write(w io.Writer, l Ledgers, vouchers []Voucher) error {
+	env := envelope{Request: "Import Data"}
+	env.Import.ReportName = "Vouchers"
+	if l.Company != "" {
+		env.Import.Static = &static{Company: l.Company}
+	}
+	for _, v := range vouchers {
+		env.Import.Messages = append(env.Import.Messages, message{Voucher: v})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(env); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
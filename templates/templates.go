@@ -0,0 +1,10 @@
+// Package templates bundles the HTML templates used to render bilty prints so
+// the server can produce PDFs without fetching anything over the network.
+package templates
+
+import "embed"
+
+// FS holds every *.html template shipped with the binary.
+//
+//go:embed *.html
+var FS embed.FS
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+)
+
+// Auditor records audit events and purges them once they are older than
+// the retention period. A nil Auditor records nothing.
+type Auditor struct {
+	Repo          repository.AuditRepository
+	Retention     time.Duration // how long events are kept; 0 keeps them forever
+	PurgeInterval time.Duration // how often expired events are purged
+	TrustProxy    bool          // take the client IP from X-Forwarded-For
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// requestMeta is what an audit event needs to know about the HTTP request
+type requestMeta struct {
+	remoteAddr   string
+	forwardedFor string
+	userAgent    string
+}
+
+type requestContextKey struct{}
+
+// WithRequest returns a context carrying the client address and user agent
+// of r, for audit events recorded while serving it
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, &requestMeta{
+		remoteAddr:   r.RemoteAddr,
+		forwardedFor: r.Header.Get("X-Forwarded-For"),
+		userAgent:    r.UserAgent(),
+	})
+}
+
+// Record stores an audit event. The actor, IP and user agent are taken from
+// ctx unless already set. Failures are logged, never returned: auditing must
+// not break the action being audited.
+func (a *Auditor) Record(ctx context.Context, e *models.AuditEvent) {
+	if a == nil || a.Repo == nil {
+		return
+	}
+	if claims := ClaimsFromContext(ctx); claims != nil && e.ActorID == nil {
+		id := claims.UserID
+		e.ActorID = &id
+		e.ActorName = claims.Name
+	}
+	if meta, ok := ctx.Value(requestContextKey{}).(*requestMeta); ok {
+		if e.IP == "" {
+			e.IP = a.clientIP(meta)
+		}
+		if e.UserAgent == "" {
+			e.UserAgent = meta.userAgent
+		}
+	}
+
+	if err := a.Repo.AddEvent(e); err != nil {
+		log.Printf("⚠️ Failed to record audit event %s on %s %s: %v", e.Action, e.Entity, e.EntityID, err)
+	}
+}
+
+// clientIP is the first X-Forwarded-For address behind a trusted proxy,
+// otherwise the address the request came from
+func (a *Auditor) clientIP(meta *requestMeta) string {
+	if a.TrustProxy && meta.forwardedFor != "" {
+		first, _, _ := strings.Cut(meta.forwardedFor, ",")
+		return strings.TrimSpace(first)
+	}
+	if host, _, err := net.SplitHostPort(meta.remoteAddr); err == nil {
+		return host
+	}
+	return meta.remoteAddr
+}
+
+// Start purges expired events now and then every PurgeInterval. It does
+// nothing when events are kept forever.
+func (a *Auditor) Start() {
+	if a == nil || a.Retention <= 0 {
+		return
+	}
+	if a.PurgeInterval <= 0 {
+		a.PurgeInterval = 24 * time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			a.purge()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the purge loop
+func (a *Auditor) Stop() {
+	if a == nil || a.cancel == nil {
+		return
+	}
+	a.cancel()
+	a.wg.Wait()
+}
+
+func (a *Auditor) purge() {
+	n, err := a.Repo.PurgeEvents(time.Now().UTC().Add(-a.Retention))
+	if err != nil {
+		log.Printf("⚠️ Failed to purge expired audit events: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d audit events older than %s", n, a.Retention)
+	}
+}
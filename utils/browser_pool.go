@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// PaperSize is a page size in inches, as expected by Chrome's PrintToPDF
+type PaperSize struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// PaperA4 is the standard A4 sheet
+var PaperA4 = PaperSize{Width: 8.27, Height: 11.7}
+
+var ErrBrowserClosed = errors.New("browser pool is closed")
+
+// BrowserPool keeps a single headless Chrome running and hands out a bounded
+// number of reusable tabs for rendering PDFs.
+type BrowserPool struct {
+	size    int
+	timeout time.Duration
+
+	mu            sync.Mutex
+	closed        bool
+	allocCancel   context.CancelFunc
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+
+	idle  chan *browserTab
+	slots chan struct{}
+}
+
+type browserTab struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewBrowserPool creates a pool of at most size tabs. Chrome is started lazily
+// on the first render. timeout bounds each render.
+func NewBrowserPool(size int, timeout time.Duration) *BrowserPool {
+	if size <= 0 {
+		size = 1
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &BrowserPool{
+		size:    size,
+		timeout: timeout,
+		idle:    make(chan *browserTab, size),
+		slots:   make(chan struct{}, size),
+	}
+}
+
+// browser starts Chrome if needed and returns the browser context
+func (p *BrowserPool) browser() (context.Context, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrBrowserClosed
+	}
+	if p.browserCtx != nil && p.browserCtx.Err() == nil {
+		return p.browserCtx, nil
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+
+	// Run with no actions launches the browser
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, fmt.Errorf("failed to start chrome: %w", err)
+	}
+
+	p.allocCancel = allocCancel
+	p.browserCtx = browserCtx
+	p.browserCancel = browserCancel
+	return browserCtx, nil
+}
+
+// acquire returns an idle tab, opens a new one if below the limit, or waits
+func (p *BrowserPool) acquire(ctx context.Context) (*browserTab, error) {
+	select {
+	case tab := <-p.idle:
+		return tab, nil
+	default:
+	}
+
+	select {
+	case tab := <-p.idle:
+		return tab, nil
+	case p.slots <- struct{}{}:
+		browserCtx, err := p.browser()
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		tabCtx, cancel := chromedp.NewContext(browserCtx)
+		return &browserTab{ctx: tabCtx, cancel: cancel}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release puts a healthy tab back in the pool and closes a broken one. The
+// check and the send share the lock with Close, so a tab can't land on idle
+// after Close has drained it. The send never blocks: idle holds one tab per slot.
+func (p *BrowserPool) release(tab *browserTab, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if healthy && !p.closed && tab.ctx.Err() == nil {
+		p.idle <- tab
+		return
+	}
+	tab.cancel()
+	<-p.slots
+}
+
+// Render loads html into a pooled tab and prints it to PDF. The render is
+// aborted when ctx is cancelled or the pool timeout elapses.
+func (p *BrowserPool) Render(ctx context.Context, html string, paper PaperSize) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	tab, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive from the tab so cancelling the render doesn't close the tab itself
+	runCtx, runCancel := context.WithCancel(tab.ctx)
+	defer runCancel()
+	stop := context.AfterFunc(ctx, runCancel)
+	defer stop()
+
+	var pdfBuf []byte
+	err = chromedp.Run(runCtx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return setContentAndWait(ctx, html)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			pdfBuf, _, err = page.PrintToPDF().
+				WithPrintBackground(true).
+				WithPaperWidth(paper.Width).
+				WithPaperHeight(paper.Height).
+				Do(ctx)
+			return err
+		}),
+	)
+	p.release(tab, err == nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to generate PDF: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return pdfBuf, nil
+}
+
+// setContentAndWait replaces the document of the main frame and waits for its load event
+func setContentAndWait(ctx context.Context, html string) error {
+	loaded := make(chan struct{})
+	var once sync.Once
+
+	listenCtx, stopListening := context.WithCancel(ctx)
+	defer stopListening()
+	chromedp.ListenTarget(listenCtx, func(ev interface{}) {
+		if _, ok := ev.(*page.EventLoadEventFired); ok {
+			once.Do(func() { close(loaded) })
+		}
+	})
+
+	frameTree, err := page.GetFrameTree().Do(ctx)
+	if err != nil {
+		return err
+	}
+	if err := page.SetDocumentContent(frameTree.Frame.ID, html).Do(ctx); err != nil {
+		return err
+	}
+
+	select {
+	case <-loaded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close shuts down all tabs and the browser
+func (p *BrowserPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+
+drain:
+	for {
+		select {
+		case tab := <-p.idle:
+			tab.cancel()
+			<-p.slots
+		default:
+			break drain
+		}
+	}
+
+	if p.browserCancel != nil {
+		p.browserCancel()
+		p.allocCancel()
+	}
+}
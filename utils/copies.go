@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// DefaultCopies is the copy set used when the initial setup doesn't configure one
+var DefaultCopies = []models.CopyConfig{
+	{Key: "consignor", Title: "Consignor Copy", Default: true},
+	{Key: "consignee", Title: "Consignee Copy", Default: true},
+	{Key: "driver", Title: "Driver Copy", Default: true},
+}
+
+// ErrInvalidPDFOptions is wrapped by errors caused by bad print options
+var ErrInvalidPDFOptions = errors.New("invalid print options")
+
+// configuredCopies returns the copy set from the initial setup, or DefaultCopies
+func configuredCopies(initial *models.InitialSetup) []models.CopyConfig {
+	if initial != nil && len(initial.Copies) > 0 {
+		return initial.Copies
+	}
+	return DefaultCopies
+}
+
+// ResolveCopies picks the copies to print. With no keys it returns the copies
+// marked default (or all of them when none is marked).
+func ResolveCopies(initial *models.InitialSetup, keys []string) ([]models.CopyConfig, error) {
+	available := configuredCopies(initial)
+
+	if len(keys) == 0 {
+		var defaults []models.CopyConfig
+		for _, c := range available {
+			if c.Default {
+				defaults = append(defaults, c)
+			}
+		}
+		if len(defaults) == 0 {
+			return available, nil
+		}
+		return defaults, nil
+	}
+
+	byKey := make(map[string]models.CopyConfig, len(available))
+	for _, c := range available {
+		byKey[c.Key] = c
+	}
+
+	copies := make([]models.CopyConfig, 0, len(keys))
+	for _, key := range keys {
+		c, ok := byKey[strings.TrimSpace(key)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown copy %q", ErrInvalidPDFOptions, key)
+		}
+		copies = append(copies, c)
+	}
+	return copies, nil
+}
+
+// ValidateCopies checks a configured copy set. Keys must be unique and use
+// only letters, digits, "-" and "_", since VariantKey and the profile and
+// language suffixes join them with "+", "@" and "~", and the copies
+// parameter separates them with commas.
+func ValidateCopies(copies []models.CopyConfig) error {
+	seen := make(map[string]bool, len(copies))
+	for i, c := range copies {
+		if c.Key == "" {
+			return fmt.Errorf("copy %d has no key", i+1)
+		}
+		for _, r := range c.Key {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Errorf("copy key %q may only use letters, digits, - and _", c.Key)
+			}
+		}
+		if seen[c.Key] {
+			return fmt.Errorf("duplicate copy key %q", c.Key)
+		}
+		seen[c.Key] = true
+	}
+	return nil
+}
+
+// VariantKey identifies a copy combination for caching, e.g. "consignor+driver"
+func VariantKey(copies []models.CopyConfig) string {
+	keys := make([]string, len(copies))
+	for i, c := range copies {
+		keys[i] = c.Key
+	}
+	return strings.Join(keys, "+")
+}
+
+// ParseCopyKeys splits a comma separated copies parameter
+func ParseCopyKeys(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+)
+
+// DefaultESCPOSColumns is the line width of an 80 mm printer in font A
+const DefaultESCPOSColumns = 48
+
+// ESC/POS commands
+var (
+	escInit      = []byte{0x1b, '@'}
+	escBoldOn    = []byte{0x1b, 'E', 1}
+	escBoldOff   = []byte{0x1b, 'E', 0}
+	escAlignLeft = []byte{0x1b, 'a', 0}
+	escAlignMid  = []byte{0x1b, 'a', 1}
+	escCut       = []byte{0x1d, 'V', 66, 0} // feed to the cutter and cut
+)
+
+// escposWriter builds a receipt line by line
+type escposWriter struct {
+	buf     bytes.Buffer
+	columns int
+}
+
+func (e *escposWriter) cmd(c []byte) { e.buf.Write(c) }
+
+// line prints s cut to the line width; it isn't padded so centring still works
+func (e *escposWriter) line(s string) {
+	s = printable(s)
+	if len(s) > e.columns {
+		s = s[:e.columns]
+	}
+	e.buf.WriteString(s)
+	e.buf.WriteByte('\n')
+}
+
+// wrap prints s over as many lines as it needs
+func (e *escposWriter) wrap(s string) {
+	line := ""
+	for _, word := range strings.Fields(printable(s)) {
+		for len(word) > e.columns {
+			if line != "" {
+				e.line(line)
+				line = ""
+			}
+			e.line(word[:e.columns])
+			word = word[e.columns:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= e.columns:
+			line += " " + word
+		default:
+			e.line(line)
+			line = word
+		}
+	}
+	if line != "" {
+		e.line(line)
+	}
+}
+
+// centered prints s centred, bold when asked
+func (e *escposWriter) centered(s string, bold bool) {
+	if s == "" {
+		return
+	}
+	e.cmd(escAlignMid)
+	if bold {
+		e.cmd(escBoldOn)
+	}
+	e.wrap(s)
+	if bold {
+		e.cmd(escBoldOff)
+	}
+	e.cmd(escAlignLeft)
+}
+
+// pair prints a label on the left and its value on the right of one line
+func (e *escposWriter) pair(label, value string) {
+	if value == "" {
+		return
+	}
+	label, value = printable(label), printable(value)
+	if len(label)+1+len(value) > e.columns {
+		e.line(label)
+		e.line(fit(value, e.columns, "right"))
+		return
+	}
+	e.line(label + fit(value, e.columns-len(label), "right"))
+}
+
+func (e *escposWriter) rule() { e.line(strings.Repeat("-", e.columns)) }
+
+// RenderBiltyESCPOS renders each copy as a receipt for an ESC/POS thermal
+// printer, cutting after every copy. columns of 0 means DefaultESCPOSColumns.
+func RenderBiltyESCPOS(in RenderInput, columns int) []byte {
+	if columns <= 0 {
+		columns = DefaultESCPOSColumns
+	}
+	e := &escposWriter{columns: columns}
+	e.cmd(escInit)
+
+	for _, c := range in.Copies {
+		data := biltyPDFData(in, c)
+		values := textFieldValues(data)
+
+		e.centered(values["company_name"], true)
+		if data.Company != nil {
+			e.centered(data.Company.Address, false)
+			if data.Company.GSTIN != "" {
+				e.centered("GSTIN: "+data.Company.GSTIN, false)
+			}
+		}
+		e.centered(data.Contacts, false)
+		e.centered(data.CopyTitle, true)
+		e.centered(data.Watermark, true)
+		e.rule()
+
+		e.pair("Bilty No", values["bilty_no"])
+		e.pair("Date", values["date"])
+		e.pair("From", values["from"])
+		e.pair("To", values["to"])
+		e.rule()
+
+		e.line("Consignor:")
+		e.wrap(values["consignor"])
+		e.wrap(values["consignor_address"])
+		e.line("Consignee:")
+		e.wrap(values["consignee"])
+		e.wrap(values["consignee_address"])
+		e.pair("GSTIN", values["gstin"])
+		e.pair("Invoice No", values["inv_no"])
+		e.pair("Pvt Marks", values["pvt_marks"])
+		e.pair("Value", values["value"])
+		e.rule()
+
+		for _, g := range in.Bilty.Goods {
+			cells := goodsFieldValues(g)
+			e.wrap(g.Particulars)
+			detail := cells["pkts"] + " pkts"
+			if cells["weight"] != "" {
+				detail += ", " + cells["weight"] + " kg"
+			}
+			if cells["rate"] != "" {
+				detail += " @ " + cells["rate"]
+				if cells["per"] != "" {
+					detail += "/" + cells["per"]
+				}
+			}
+			if cells["amount"] == "" {
+				e.line("  " + detail)
+				continue
+			}
+			e.pair("  "+detail, cells["amount"])
+		}
+		e.rule()
+
+		e.pair("Hamali", values["hamali"])
+		e.pair("DD Charges", values["dd_charges"])
+		e.pair("Other Charges", values["other_charges"])
+		e.cmd(escBoldOn)
+		e.pair("Total", values["total"])
+		e.cmd(escBoldOff)
+		e.wrap(values["total_words"])
+		if values["remarks"] != "" {
+			e.wrap("Remarks: " + values["remarks"])
+		}
+		for _, note := range data.CopyNotes {
+			e.wrap(note)
+		}
+
+		e.cmd(escCut)
+	}
+	return e.buf.Bytes()
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hariomtransport/backend/amountwords"
+	"github.com/hariomtransport/backend/models"
+)
+
+// Languages a bilty can be printed in
+const (
+	LanguageEnglish   = "en"
+	LanguageHindi     = "hi"
+	LanguageBilingual = "bilingual" // English and Hindi side by side
+)
+
+// printLabels are the captions templates read from BiltyPDFData.Labels, in English and Hindi
+var printLabels = map[string][2]string{
+	"bilty_no":      {"Bilty No", "बिल्टी नं."},
+	"date":          {"Date", "दिनांक"},
+	"consignor":     {"Consignor", "प्रेषक"},
+	"consignee":     {"Consignee", "प्रेषिती"},
+	"gstin":         {"GSTIN", "जीएसटीआईएन"},
+	"from":          {"From", "से"},
+	"to":            {"To", "तक"},
+	"particulars":   {"Particulars", "विवरण"},
+	"pkts":          {"No. of Pkt", "पैकेट"},
+	"weight":        {"Weight (Kg)", "वज़न (कि.ग्रा.)"},
+	"rate":          {"Rate", "दर"},
+	"per":           {"Per", "प्रति"},
+	"amount":        {"Amount", "राशि"},
+	"total":         {"Total", "कुल"},
+	"hamali":        {"Hamali", "हमाली"},
+	"dd_charges":    {"D.D CH", "डी.डी. शुल्क"},
+	"other_charges": {"Other CH", "अन्य शुल्क"},
+	"fov":           {"F.O.V", "एफ.ओ.वी."},
+	"inv_date":      {"Inv Date", "बीजक दिनांक"},
+	"inv_no":        {"Inv No", "बीजक नं."},
+	"pvt_marks":     {"PVT Marks", "निजी चिह्न"},
+	"permit_no":     {"Permit No", "परमिट नं."},
+	"statistical":   {"Statistical", "सांख्यिकीय"},
+	"value":         {"Value Rs", "मूल्य रु."},
+	"remarks":       {"Remarks", "टिप्पणी"},
+	"in_words":      {"Rs. (in words)", "रु. (शब्दों में)"},
+	"branch":        {"Our Branch", "हमारी शाखा"},
+}
+
+// ResolveLanguage returns the requested language, falling back to the
+// company's and then English
+func ResolveLanguage(initial *models.InitialSetup, requested string) (string, error) {
+	lang := strings.ToLower(strings.TrimSpace(requested))
+	if lang == "" && initial != nil {
+		lang = initial.Language
+	}
+	switch lang {
+	case "":
+		return LanguageEnglish, nil
+	case LanguageEnglish, LanguageHindi, LanguageBilingual:
+		return lang, nil
+	}
+	return "", fmt.Errorf("%w: unknown language %q", ErrInvalidPDFOptions, lang)
+}
+
+// PrintLabels returns the template captions for a language
+func PrintLabels(lang string) map[string]string {
+	labels := make(map[string]string, len(printLabels))
+	for key, l := range printLabels {
+		switch lang {
+		case LanguageHindi:
+			labels[key] = l[1]
+		case LanguageBilingual:
+			labels[key] = l[0] + " / " + l[1]
+		default:
+			labels[key] = l[0]
+		}
+	}
+	return labels
+}
+
+// amountInWords returns the amount in words in the print language and, for a
+// bilingual print, the Hindi line printed below it
+func amountInWords(amount models.Money, lang string) (string, string) {
+	english := amountwords.Default
+	hindi := amountwords.Converter{Language: amountwords.Hindi}
+	switch lang {
+	case LanguageHindi:
+		return hindi.Paise(amount.Paise()), ""
+	case LanguageBilingual:
+		return english.Paise(amount.Paise()), hindi.Paise(amount.Paise())
+	}
+	return english.Paise(amount.Paise()), ""
+}
+
+// languageVariant keeps prints in a language other than the company's own
+// apart in the PDF cache
+func languageVariant(variant, lang string, initial *models.InitialSetup) string {
+	companyLang, _ := ResolveLanguage(initial, "")
+	if lang == "" || lang == companyLang {
+		return variant
+	}
+	return variant + "~" + lang
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// BulkFileName is the name of a single bilty PDF inside bulk downloads
+func BulkFileName(b *models.Bilty) string {
+	return fmt.Sprintf("bilty_%d.pdf", b.BiltyNo)
+}
+
+// bulkSetup loads the initial setup and resolves the copies, profile and
+// language shared by every bilty; Bilty is left for the caller to fill in
+func (g *PDFGenerator) bulkSetup(opts models.PDFOptions) (RenderInput, error) {
+	initial, err := g.Repo.GetInitialForPDF()
+	if err != nil {
+		return RenderInput{}, err
+	}
+	copies, err := ResolveCopies(initial, opts.Copies)
+	if err != nil {
+		return RenderInput{}, err
+	}
+	profile, err := ResolveProfile(opts.Profile)
+	if err != nil {
+		return RenderInput{}, err
+	}
+	lang, err := ResolveLanguage(initial, opts.Language)
+	if err != nil {
+		return RenderInput{}, err
+	}
+	return RenderInput{Initial: initial, Copies: copies, Reprint: opts.Reprint, Profile: profile, Language: lang}, nil
+}
+
+// WriteBulkZIP streams one PDF per bilty into a ZIP archive, writing each
+// entry as soon as it is ready
+func (g *PDFGenerator) WriteBulkZIP(ctx context.Context, w io.Writer, bilties []*models.Bilty, opts models.PDFOptions) error {
+	in, err := g.bulkSetup(opts)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, b := range bilties {
+		in.Bilty = b
+		pdfBytes, err := g.BiltyPDFBytes(ctx, in)
+		if err != nil {
+			return fmt.Errorf("bilty %d: %w", b.BiltyNo, err)
+		}
+
+		// PDFs are already compressed, so store them as-is
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: BulkFileName(b), Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(pdfBytes); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// RenderBulk collects the PDF of every bilty, in order
+func (g *PDFGenerator) RenderBulk(ctx context.Context, bilties []*models.Bilty, opts models.PDFOptions) ([][]byte, error) {
+	in, err := g.bulkSetup(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pdfs := make([][]byte, 0, len(bilties))
+	for _, b := range bilties {
+		in.Bilty = b
+		pdfBytes, err := g.BiltyPDFBytes(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("bilty %d: %w", b.BiltyNo, err)
+		}
+		pdfs = append(pdfs, pdfBytes)
+	}
+	return pdfs, nil
+}
+
+// MergePDFs writes the given PDFs as one multi-page document
+func MergePDFs(w io.Writer, pdfs [][]byte) error {
+	if len(pdfs) == 1 {
+		_, err := w.Write(pdfs[0])
+		return err
+	}
+
+	readers := make([]io.ReadSeeker, len(pdfs))
+	for i, p := range pdfs {
+		readers[i] = bytes.NewReader(p)
+	}
+	if err := api.MergeRaw(readers, w, false, nil); err != nil {
+		return fmt.Errorf("failed to merge PDFs: %w", err)
+	}
+	return nil
+}
+
+// ValidateOptions checks print options up front, before a streamed response starts
+func (g *PDFGenerator) ValidateOptions(opts models.PDFOptions) error {
+	_, err := g.bulkSetup(opts)
+	return err
+}
@@ -3,36 +3,107 @@ package utils
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
-	"net/http"
-	"os"
 	"time"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/hariomtransport/backend/models"
 	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/storage"
 )
 
-// GenerateBiltyPDF fetches the template from the URL specified in TEMPLATE_FILE env variable
-// and generates a PDF.
-func GenerateBiltyPDF(repo *repository.PDFRepository, biltyID int64) ([]byte, error) {
-	// Fetch initial setup
-	initial, err := repo.GetInitialForPDF()
+// PDFGenerator renders bilties to PDF using the active print template
+type PDFGenerator struct {
+	Repo      *repository.PDFRepository
+	Templates *TemplateStore
+	Browser   *BrowserPool
+	Storage   storage.Storage
+	// LinkExpiry is how long signed PDF links stay valid
+	LinkExpiry time.Duration
+
+	// Signer and PublicBaseURL produce the verification QR code; without a
+	// base URL no QR code is printed
+	Signer        *BiltySigner
+	PublicBaseURL string
+
+	// Audit records deleted PDFs; optional
+	Audit *Auditor
+}
+
+// RenderInput is everything needed to render one bilty print
+type RenderInput struct {
+	Initial  *models.InitialSetup
+	Bilty    *models.Bilty
+	Copies   []models.CopyConfig
+	QRCode   template.URL // data URI of the verification QR code, optional
+	Reprint  bool         // the bilty was issued before, print it as a duplicate
+	Profile  PrintProfile // layout and paper, the default profile when empty
+	Language string       // LanguageEnglish, LanguageHindi or LanguageBilingual; English when empty
+}
+
+// preparedPrint is a print with its template resolved and its cache key computed
+type preparedPrint struct {
+	in   RenderInput
+	tmpl *template.Template
+	hash string
+}
+
+// prepare resolves the active template and QR code of a print and hashes it,
+// so the cached PDF can be checked before anything is rendered
+func (g *PDFGenerator) prepare(in RenderInput) (*preparedPrint, error) {
+	if in.Profile.Name == "" {
+		in.Profile = PrintProfiles[DefaultProfile]
+	}
+	tmpl, version, err := g.Templates.Active(in.Profile.Template)
+	if err != nil {
+		return nil, err
+	}
+	if in.QRCode, err = g.verificationQR(in.Bilty.ID); err != nil {
+		return nil, err
+	}
+	hash, err := PDFHash(in, version)
+	if err != nil {
+		return nil, err
+	}
+	return &preparedPrint{in: in, tmpl: tmpl, hash: hash}, nil
+}
+
+// render turns a prepared print into a PDF. Rendering stops when ctx is cancelled.
+func (g *PDFGenerator) render(ctx context.Context, p *preparedPrint) ([]byte, error) {
+	finalHTML, err := RenderBiltyHTML(p.tmpl, p.in)
 	if err != nil {
 		return nil, err
 	}
+	return g.Browser.Render(ctx, finalHTML, p.in.Profile.Paper)
+}
 
-	// Fetch bilty
-	bilty, err := repo.GetBiltyForPDF(biltyID)
+// RenderBilty prints an already loaded bilty with the active template.
+// Rendering stops when ctx is cancelled.
+func (g *PDFGenerator) RenderBilty(ctx context.Context, in RenderInput) ([]byte, error) {
+	p, err := g.prepare(in)
 	if err != nil {
 		return nil, err
 	}
-	if bilty == nil {
-		return nil, nil
+	return g.render(ctx, p)
+}
+
+// verificationQR returns the QR code linking to the bilty's public verification page
+func (g *PDFGenerator) verificationQR(biltyID int64) (template.URL, error) {
+	if g.Signer == nil || g.PublicBaseURL == "" || biltyID == 0 {
+		return "", nil
 	}
+	qr, err := QRDataURI(g.Signer.VerifyURL(g.PublicBaseURL, biltyID), 256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	return qr, nil
+}
+
+// biltyPDFData is what a bilty template (or the text renderers) see for one copy
+func biltyPDFData(in RenderInput, c models.CopyConfig) models.BiltyPDFData {
+	initial, bilty := in.Initial, in.Bilty
 
 	// Format bilty date safely
 	formattedBiltyDate := "-"
@@ -42,108 +113,320 @@ func GenerateBiltyPDF(repo *repository.PDFRepository, biltyID int64) ([]byte, er
 
 	// Prepare contact numbers
 	contacts := ""
-	for _, m := range initial.Mobile {
-		contacts += m.Number + "(" + m.Label + "), "
+	if initial != nil {
+		for _, m := range initial.Mobile {
+			contacts += m.Number + "(" + m.Label + "), "
+		}
 	}
 	if len(contacts) > 2 {
 		contacts = contacts[:len(contacts)-2]
 	}
 
-	// Copy titles
-	copyTitles := []string{"Consignor Copy", "Consignee Copy", "Driver Copy"}
-
-	// --- Fetch template from URL in TEMPLATE_FILE ---
-	templateURL := os.Getenv("TEMPLATE_FILE")
-	if templateURL == "" {
-		return nil, fmt.Errorf("TEMPLATE_FILE environment variable not set")
-	}
-
-	resp, err := http.Get(templateURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch template: %w", err)
+	lang := in.Language
+	if lang == "" {
+		lang = LanguageEnglish
 	}
-	defer resp.Body.Close()
+	totalWords, totalWordsAlt := amountInWords(bilty.ToPay, lang)
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch template, status code: %d", resp.StatusCode)
+	return models.BiltyPDFData{
+		Company:       initial,
+		Bilty:         bilty,
+		Contacts:      contacts,
+		Date:          formattedBiltyDate,
+		Total:         bilty.ToPay,
+		TotalWords:    totalWords,
+		TotalWordsAlt: totalWordsAlt,
+		CopyTitle:     c.Title,
+		CopyColor:     c.Color,
+		CopyNotes:     c.Footnote,
+		GoodsCount:    len(bilty.Goods),
+		QRCode:        in.QRCode,
+		Watermark:     BiltyWatermark(bilty.Status, in.Reprint),
+		Language:      lang,
+		Labels:        PrintLabels(lang),
 	}
+}
 
-	tmplBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read template body: %w", err)
+// RenderBiltyHTML executes tmpl once per copy and wraps the copies in a page
+// laid out by the print profile
+func RenderBiltyHTML(tmpl *template.Template, in RenderInput) (string, error) {
+	profile := in.Profile
+	if profile.Name == "" {
+		profile = PrintProfiles[DefaultProfile]
 	}
 
-	tmpl, err := template.New("bilty").Parse(string(tmplBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
-	}
+	watermark := BiltyWatermark(in.Bilty.Status, in.Reprint)
 
 	var fullHTML bytes.Buffer
-	for _, title := range copyTitles {
-		data := models.BiltyPDFData{
-			Company:    initial,
-			Bilty:      bilty,
-			Contacts:   contacts,
-			Date:       formattedBiltyDate,
-			Total:      bilty.ToPay,
-			TotalWords: NumberToCurrencyWords(bilty.ToPay),
-			CopyTitle:  title,
-			GoodsCount: len(bilty.Goods),
-		}
+	for _, c := range in.Copies {
+		data := biltyPDFData(in, c)
 
 		var buf bytes.Buffer
 		if err := tmpl.Execute(&buf, data); err != nil {
-			return nil, fmt.Errorf("failed to execute template: %w", err)
+			return "", fmt.Errorf("failed to execute template: %w", err)
 		}
 
 		fullHTML.WriteString("<div class='bilty-copy'>")
+		fullHTML.WriteString(watermarkOverlay(watermark))
 		fullHTML.Write(buf.Bytes())
 		fullHTML.WriteString("</div>")
 	}
 
-	finalHTML := `
+	return `
 	<!DOCTYPE html>
 	<html>
 	<head>
 	<meta charset="UTF-8">
 	<style>
-	@page { size: A4; margin: 20px; }
-	body { font-family: Arial, Helvetica, sans-serif; font-size: 12px; margin:0; padding:0; }
-	.bilty-copy { page-break-inside: avoid; border:none; }
+	body { font-family: Arial, Helvetica, "Noto Sans Devanagari", sans-serif; font-size: 12px; margin:0; padding:0; }
+	.bilty-copy { border:none; position: relative; }
+	.bilty-watermark {
+		position: absolute; top: 50%; left: 50%; z-index: 10; pointer-events: none;
+		transform: translate(-50%, -50%) rotate(-30deg);
+		font-size: 72px; font-weight: bold; letter-spacing: 8px;
+		color: rgba(200, 0, 0, 0.18); white-space: nowrap;
+	}
+	` + profile.PageCSS + `
 	</style>
 	</head>
-	<body>` + fullHTML.String() + `</body></html>`
+	<body>` + fullHTML.String() + `</body></html>`, nil
+}
+
+// PDFKeyPrefix is the common prefix of stored bilty PDFs
+const PDFKeyPrefix = "bilty/"
+
+// PDFKey is where a rendered PDF is stored:
+// bilty/<year>/<month>/<bilty id>/<variant>_<unix time>.pdf, filed by bilty date
+func PDFKey(bilty *models.Bilty, variant string, t time.Time) string {
+	filed := bilty.Date
+	if filed.IsZero() {
+		filed = t
+	}
+	return fmt.Sprintf("%s%04d/%02d/%d/%s_%d.pdf", PDFKeyPrefix, filed.Year(), filed.Month(), bilty.ID, variant, t.Unix())
+}
+
+// ErrBiltyNotFound is returned when the bilty to print does not exist
+var ErrBiltyNotFound = errors.New("bilty not found")
+
+// cachedPDF returns the stored PDF of the variant and whether it was rendered from the same content
+func (g *PDFGenerator) cachedPDF(biltyID int64, variant, hash string) (*models.BiltyPDF, bool, error) {
+	stored, err := g.Repo.BiltyRepo.GetPDFVariant(biltyID, variant)
+	if err != nil || stored == nil {
+		return nil, false, err
+	}
+	return stored, stored.Hash == hash, nil
+}
+
+// PublishBiltyPDF makes sure an up-to-date PDF of the bilty with the requested
+// copies is stored and returns its location. fresh reports whether a new PDF
+// had to be generated, which happens only when the PDF's content hash changes.
+// Each copy combination is stored separately.
+func (g *PDFGenerator) PublishBiltyPDF(ctx context.Context, biltyID int64, opts models.PDFOptions) (string, bool, error) {
+	// Fetch bilty record
+	bilty, err := g.Repo.GetBiltyForPDF(biltyID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch bilty: %w", err)
+	}
+	if bilty == nil {
+		return "", false, ErrBiltyNotFound
+	}
+
+	initial, err := g.Repo.GetInitialForPDF()
+	if err != nil {
+		return "", false, err
+	}
+
+	copies, err := ResolveCopies(initial, opts.Copies)
+	if err != nil {
+		return "", false, err
+	}
+	profile, err := ResolveProfile(opts.Profile)
+	if err != nil {
+		return "", false, err
+	}
+	lang, err := ResolveLanguage(initial, opts.Language)
+	if err != nil {
+		return "", false, err
+	}
+	in := RenderInput{Initial: initial, Bilty: bilty, Copies: copies, Reprint: opts.Reprint, Profile: profile, Language: lang}
+
+	variant, err := g.printVariant(&in)
+	if err != nil {
+		return "", false, err
+	}
+	reprint := in.Reprint
+
+	prepared, err := g.prepare(in)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Reuse existing PDF if still valid
+	stored, upToDate, err := g.cachedPDF(biltyID, variant, prepared.hash)
+	if err != nil {
+		return "", false, err
+	}
+	if upToDate {
+		g.countReprint(biltyID, reprint)
+		return stored.PdfPath, false, nil
+	}
+
+	// Generate new PDF
+	pdfBytes, err := g.render(ctx, prepared)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Upload PDF
+	key := PDFKey(bilty, variant, time.Now())
+	if err := g.Storage.Put(ctx, key, pdfBytes, "application/pdf"); err != nil {
+		return "", false, fmt.Errorf("failed to store PDF: %w", err)
+	}
+
+	// Update database; an object nothing points to would be orphaned, so drop it
+	now := time.Now().UTC()
+	if err := g.Repo.BiltyRepo.SavePDFVariant(&models.BiltyPDF{
+		BiltyID: biltyID, Variant: variant, PdfPath: key, Hash: prepared.hash, CreatedAt: now,
+	}); err != nil {
+		if delErr := g.Storage.Delete(context.Background(), key); delErr != nil {
+			fmt.Printf("⚠️ Failed to delete unsaved PDF %s: %v\n", key, delErr)
+		} else {
+			g.auditPDFDeleted(ctx, biltyID, key, "unsaved")
+		}
+		return "", false, fmt.Errorf("failed to save PDF variant: %w", err)
+	}
+
+	// The default copy set, profile and language stay mirrored on the bilty row
+	defaults, _ := ResolveCopies(initial, nil)
+	if variant == VariantKey(defaults) {
+		if err := g.Repo.BiltyRepo.UpdatePDFInfo(biltyID, key, prepared.hash, now); err != nil {
+			fmt.Printf("⚠️ Failed to update PDF info for bilty %d: %v\n", biltyID, err)
+		}
+	}
+
+	// Delete old PDF
+	var previous interface{}
+	if stored != nil && stored.PdfPath != "" {
+		previous = stored.PdfPath
+		if err := g.Storage.Delete(ctx, storage.KeyFromLocation(stored.PdfPath)); err != nil {
+			fmt.Printf("⚠️ Failed to delete old PDF for bilty %d: %v\n", biltyID, err)
+		} else {
+			g.auditPDFDeleted(ctx, biltyID, storage.KeyFromLocation(stored.PdfPath), "replaced")
+		}
+	}
+
+	if err := g.Repo.BiltyRepo.AddHistory(&models.BiltyHistory{
+		BiltyID: biltyID,
+		Action:  models.HistoryPDF,
+		UserID:  ActorID(ctx),
+		Changes: []models.FieldChange{{Field: "pdf:" + variant, Old: previous, New: key}},
+	}); err != nil {
+		fmt.Printf("⚠️ Failed to record PDF generation for bilty %d: %v\n", biltyID, err)
+	}
+
+	g.countReprint(biltyID, reprint)
+	return key, true, nil
+}
 
-	// Create temp HTML file
-	tmpFile := fmt.Sprintf("/tmp/bilty_%s.html", time.Now().UTC().Format("20060102150405"))
-	if err := os.WriteFile(tmpFile, []byte(finalHTML), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write temp HTML: %w", err)
+// auditPDFDeleted records that a stored PDF of the bilty was deleted, and why
+func (g *PDFGenerator) auditPDFDeleted(ctx context.Context, biltyID int64, key, reason string) {
+	g.Audit.Record(ctx, &models.AuditEvent{
+		Action:   models.AuditPDFDeleted,
+		Entity:   models.AuditEntityPDF,
+		EntityID: key,
+		Payload:  map[string]interface{}{"bilty_id": biltyID, "reason": reason},
+	})
+}
+
+// defaultLinkExpiry applies when LinkExpiry isn't set
+const defaultLinkExpiry = 15 * time.Minute
+
+// SignedPDFURL returns a time-limited download link for a stored PDF and when it expires
+func (g *PDFGenerator) SignedPDFURL(ctx context.Context, location string) (string, time.Time, error) {
+	expiry := g.LinkExpiry
+	if expiry <= 0 {
+		expiry = defaultLinkExpiry
+	}
+	link, err := g.Storage.SignedURL(ctx, storage.KeyFromLocation(location), expiry)
+	if err != nil {
+		return "", time.Time{}, err
 	}
-	defer os.Remove(tmpFile)
+	return link, time.Now().Add(expiry).UTC(), nil
+}
 
-	fileURL := "file://" + tmpFile
+// OpenPDF streams a stored PDF; the caller must close it
+func (g *PDFGenerator) OpenPDF(ctx context.Context, location string) (io.ReadCloser, error) {
+	return g.Storage.Open(ctx, storage.KeyFromLocation(location))
+}
 
-	// Generate PDF with headless Chrome
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
+// BiltyPDFBytes returns the stored PDF of the print when it is still up to
+// date, and renders a fresh one otherwise. in.Reprint asks for a duplicate.
+func (g *PDFGenerator) BiltyPDFBytes(ctx context.Context, in RenderInput) ([]byte, error) {
+	bilty := in.Bilty
+	variant, err := g.printVariant(&in)
+	if err != nil {
+		return nil, err
+	}
+	reprint := in.Reprint
+	prepared, err := g.prepare(in)
+	if err != nil {
+		return nil, err
+	}
+	stored, upToDate, err := g.cachedPDF(bilty.ID, variant, prepared.hash)
+	if err != nil {
+		return nil, err
+	}
+	if upToDate {
+		pdfBytes, err := g.Storage.Get(ctx, storage.KeyFromLocation(stored.PdfPath))
+		if err == nil {
+			g.countReprint(bilty.ID, reprint)
+			return pdfBytes, nil
+		}
+		fmt.Printf("⚠️ Failed to reuse stored PDF for bilty %d, rendering again: %v\n", bilty.ID, err)
+	}
 
-	var pdfBuf []byte
-	err = chromedp.Run(ctx,
-		chromedp.Navigate(fileURL),
-		chromedp.Sleep(2*time.Second), // ensure page fully loads
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			pdfBuf, _, err = page.PrintToPDF().
-				WithPrintBackground(true).
-				WithPaperWidth(8.27).  // A4 width
-				WithPaperHeight(11.7). // A4 height
-				Do(ctx)
-			return err
-		}),
-	)
+	pdfBytes, err := g.render(ctx, prepared)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+		return nil, err
+	}
+	g.countReprint(bilty.ID, reprint)
+	return pdfBytes, nil
+}
+
+// printVariant returns the cache key of the PDF to print. in.Reprint asks for
+// a duplicate and is cleared unless the bilty can be reprinted: only a
+// complete bilty that was issued before can be.
+func (g *PDFGenerator) printVariant(in *RenderInput) (string, error) {
+	bilty := in.Bilty
+	variant := profileVariant(VariantKey(in.Copies), in.Profile)
+	variant = languageVariant(variant, in.Language, in.Initial)
+	if !in.Reprint || BiltyWatermark(bilty.Status, true) != WatermarkDuplicate {
+		in.Reprint = false
+		return variant, nil
 	}
 
-	return pdfBuf, nil
+	issued := bilty.PdfCreatedAt != nil
+	if !issued {
+		original, err := g.Repo.BiltyRepo.GetPDFVariant(bilty.ID, variant)
+		if err != nil {
+			return "", err
+		}
+		issued = original != nil
+	}
+	if !issued {
+		in.Reprint = false
+		return variant, nil
+	}
+
+	return variant + duplicateVariantSuffix, nil
+}
+
+// countReprint records a delivered duplicate on the bilty
+func (g *PDFGenerator) countReprint(biltyID int64, reprint bool) {
+	if !reprint {
+		return
+	}
+	if err := g.Repo.BiltyRepo.IncrementReprintCount(biltyID); err != nil {
+		fmt.Printf("⚠️ Failed to count reprint of bilty %d: %v\n", biltyID, err)
+	}
 }
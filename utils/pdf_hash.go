@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// pdfFingerprint is everything that ends up on a printed bilty
+type pdfFingerprint struct {
+	Bilty           models.Bilty         `json:"bilty"`
+	Initial         *models.InitialSetup `json:"initial"` // the setup's content is its version
+	Profile         string               `json:"profile"`
+	Language        string               `json:"language"`
+	TemplateVersion string               `json:"template_version"`
+	Copies          []models.CopyConfig  `json:"copies"`
+	Watermark       string               `json:"watermark"`
+	QRCode          template.URL         `json:"qr_code"`
+}
+
+// PDFHash returns the cache key of a print: a hash of the bilty data, the
+// initial setup, the profile and its template version, the language and the
+// copy set. Bookkeeping fields that don't show on paper are left out, so
+// setting them doesn't force a new PDF.
+func PDFHash(in RenderInput, templateVersion string) (string, error) {
+	bilty := *in.Bilty
+	bilty.UpdatedAt = nil
+	bilty.UpdatedBy = nil
+	bilty.PdfCreatedAt = nil
+	bilty.PdfPath = nil
+	bilty.PdfHash = nil
+	bilty.ReprintCount = 0
+	bilty.DeliveryStatus = ""
+	bilty.BelowContract = false
+	bilty.TallyExportedAt = nil
+	bilty.TallyExportID = nil
+
+	data, err := json.Marshal(pdfFingerprint{
+		Bilty:           bilty,
+		Initial:         in.Initial,
+		Profile:         in.Profile.Name,
+		Language:        in.Language,
+		TemplateVersion: templateVersion,
+		Copies:          in.Copies,
+		Watermark:       BiltyWatermark(bilty.Status, in.Reprint),
+		QRCode:          in.QRCode,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
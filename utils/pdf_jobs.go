@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+)
+
+// PDFJobRunner processes queued PDF jobs with a fixed number of workers.
+// Failed jobs are retried with exponential backoff up to MaxAttempts.
+type PDFJobRunner struct {
+	Repo         repository.JobRepository
+	Generator    *PDFGenerator
+	Workers      int
+	MaxAttempts  int
+	BaseBackoff  time.Duration // delay before the first retry, doubled on each attempt
+	PollInterval time.Duration // how often idle workers check for due jobs
+	Lease        time.Duration // how long a job may run before it is taken to be abandoned and requeued
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// maxBackoff caps the delay between retries
+const maxBackoff = 10 * time.Minute
+
+// Start launches the workers, and a reaper that requeues jobs whose worker
+// died mid-run: running longer than the lease, whichever process claimed them
+func (j *PDFJobRunner) Start() {
+	if j.Workers <= 0 {
+		j.Workers = 1
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = 3
+	}
+	if j.BaseBackoff <= 0 {
+		j.BaseBackoff = 10 * time.Second
+	}
+	if j.PollInterval <= 0 {
+		j.PollInterval = 5 * time.Second
+	}
+	if j.Lease <= 0 {
+		j.Lease = 2 * time.Minute
+	}
+	j.wake = make(chan struct{}, j.Workers)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.wg.Add(1)
+	go j.reaper(ctx)
+	for i := 0; i < j.Workers; i++ {
+		j.wg.Add(1)
+		go j.worker(ctx)
+	}
+}
+
+// Stop cancels in-flight jobs, which go straight back to the queue, and
+// waits for the workers to exit
+func (j *PDFJobRunner) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	j.wg.Wait()
+}
+
+// Submit queues a PDF job for the bilty and wakes an idle worker
+func (j *PDFJobRunner) Submit(biltyID int64, opts models.PDFOptions) (*models.PDFJob, error) {
+	job := &models.PDFJob{BiltyID: biltyID, Options: opts, MaxAttempts: j.MaxAttempts}
+	if err := j.Repo.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (j *PDFJobRunner) reaper(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.Lease)
+	defer ticker.Stop()
+
+	for {
+		if n, err := j.Repo.RequeueRunning(time.Now().UTC().Add(-j.Lease)); err != nil {
+			log.Printf("⚠️ Failed to requeue abandoned PDF jobs: %v", err)
+		} else if n > 0 {
+			log.Printf("Requeued %d abandoned PDF jobs", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PDFJobRunner) worker(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every due job before going back to sleep
+		for ctx.Err() == nil {
+			job, err := j.Repo.ClaimJob(time.Now().UTC())
+			if err != nil {
+				log.Printf("⚠️ Failed to claim PDF job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			j.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PDFJobRunner) run(ctx context.Context, job *models.PDFJob) {
+	location, _, err := j.Generator.PublishBiltyPDF(ctx, job.BiltyID, job.Options)
+	if err == nil {
+		settled(job, "mark done", j.Repo.CompleteJob(job.ID, job.Attempts, location))
+		return
+	}
+
+	// Interrupted by shutdown: put the job straight back for the next start
+	if ctx.Err() != nil {
+		settled(job, "requeue", j.Repo.RetryJob(job.ID, job.Attempts, err.Error(), time.Now().UTC()))
+		return
+	}
+
+	// A missing bilty or bad options will never succeed, and attempts are exhausted after MaxAttempts
+	if errors.Is(err, ErrBiltyNotFound) || errors.Is(err, ErrInvalidPDFOptions) || job.Attempts >= job.MaxAttempts {
+		settled(job, "mark failed", j.Repo.FailJob(job.ID, job.Attempts, err.Error()))
+		return
+	}
+
+	backoff := j.BaseBackoff << (job.Attempts - 1)
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	settled(job, "reschedule", j.Repo.RetryJob(job.ID, job.Attempts, err.Error(), time.Now().UTC().Add(backoff)))
+}
+
+// settled logs a failure to record a job's outcome. A job requeued after its
+// lease expired belongs to whichever worker claimed it next, so this
+// worker's outcome is dropped.
+func settled(job *models.PDFJob, action string, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrJobLost):
+		log.Printf("⚠️ PDF job %d outlived its lease and was requeued; not recording attempt %d", job.ID, job.Attempts)
+	default:
+		log.Printf("⚠️ Failed to %s PDF job %d: %v", action, job.ID, err)
+	}
+}
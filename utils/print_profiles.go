@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrintProfile is a named print layout with its own template and paper size
+type PrintProfile struct {
+	Name     string    `json:"name"`
+	Label    string    `json:"label"`
+	Template string    `json:"template"` // template name in the TemplateStore
+	Paper    PaperSize `json:"paper"`
+	PageCSS  string    `json:"-"` // @page and copy layout rules added to the page
+}
+
+// DefaultProfile is used when no profile is requested
+const DefaultProfile = "a4"
+
+// PaperA5 is the A5 pre-printed stationery
+var PaperA5 = PaperSize{Width: 5.83, Height: 8.27}
+
+// PaperThermal80 is an 80 mm receipt roll; each copy is cut as its own page
+var PaperThermal80 = PaperSize{Width: 3.15, Height: 11.7}
+
+// PrintProfiles are the layouts /bilty/pdf can print with
+var PrintProfiles = map[string]PrintProfile{
+	"a4": {
+		Name:     "a4",
+		Label:    "A4, three copies per sheet",
+		Template: DefaultTemplateName,
+		Paper:    PaperA4,
+		PageCSS: `@page { size: A4; margin: 20px; }
+	.bilty-copy { page-break-inside: avoid; }`,
+	},
+	"a5": {
+		Name:     "a5",
+		Label:    "A5 stationery, one copy per sheet",
+		Template: "bilty_a5",
+		Paper:    PaperA5,
+		PageCSS: `@page { size: A5; margin: 12px; }
+	.bilty-copy { page-break-after: always; }
+	.bilty-copy:last-child { page-break-after: auto; }
+	.bilty-watermark { font-size: 56px; }`,
+	},
+	"thermal80": {
+		Name:     "thermal80",
+		Label:    "80 mm thermal receipt",
+		Template: "bilty_thermal",
+		Paper:    PaperThermal80,
+		PageCSS: `@page { size: 80mm 297mm; margin: 2mm; }
+	body { font-family: "Courier New", "Noto Sans Devanagari", monospace; font-size: 11px; }
+	.bilty-copy { page-break-after: always; }
+	.bilty-copy:last-child { page-break-after: auto; }
+	.bilty-watermark { font-size: 30px; letter-spacing: 2px; }`,
+	},
+}
+
+// ResolveProfile returns the named profile, or the default one for an empty name
+func ResolveProfile(name string) (PrintProfile, error) {
+	if strings.TrimSpace(name) == "" {
+		name = DefaultProfile
+	}
+	p, ok := PrintProfiles[strings.TrimSpace(name)]
+	if !ok {
+		return PrintProfile{}, fmt.Errorf("%w: unknown print profile %q", ErrInvalidPDFOptions, name)
+	}
+	return p, nil
+}
+
+// ProfileForTemplate returns the profile printing with the named template, or the default one
+func ProfileForTemplate(name string) PrintProfile {
+	for _, p := range PrintProfiles {
+		if p.Template == name {
+			return p
+		}
+	}
+	return PrintProfiles[DefaultProfile]
+}
+
+// ProfileNames lists the available profiles, sorted
+func ProfileNames() []string {
+	names := make([]string, 0, len(PrintProfiles))
+	for name := range PrintProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileVariant extends a copy set's variant key with the profile, so each
+// profile is cached separately. The default profile keeps the bare key.
+func profileVariant(variant string, profile PrintProfile) string {
+	if profile.Name == "" || profile.Name == DefaultProfile {
+		return variant
+	}
+	return variant + "@" + profile.Name
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"encoding/base64"
+	"html/template"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRDataURI encodes content as a PNG QR code embedded in a data URI
+func QRDataURI(content string, size int) (template.URL, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
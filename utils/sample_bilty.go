@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// SampleBilty returns a filled-in bilty used to preview templates
+func SampleBilty() *models.Bilty {
+	str := func(s string) *string { return &s }
+	num := func(f float64) *float64 { return &f }
+	rs := func(f float64) *models.Money { return models.NewMoney(models.MoneyFromRupees(f)) }
+
+	return &models.Bilty{
+		BiltyNo:      1001,
+		FromLocation: "Bihar Sharif",
+		ToLocation:   "Patna",
+		Date:         time.Now(),
+		ToPay:        models.MoneyFromRupees(2450),
+		InvNo:        str("INV-2045"),
+		PVTMarks:     str("HOT"),
+		PermitNo:     str("PRM-77"),
+		ValueRupees:  rs(85000),
+		Remarks:      str("Handle with care"),
+		Hamali:       rs(100),
+		DDCharges:    rs(50),
+		OtherCharges: rs(0),
+		FOV:          rs(0),
+		Statistical:  str("-"),
+		Status:       "complete",
+		ConsignorCompany: &models.Company{
+			Name:  "Sample Traders",
+			GSTIN: str("10AAAAA0000A1Z5"),
+		},
+		ConsigneeCompany: &models.Company{
+			Name:  "Example Distributors",
+			GSTIN: str("10BBBBB1111B1Z6"),
+		},
+		ConsignorAddressSnap: &models.BiltyAddress{
+			AddressLine: "Station Road",
+			City:        "Bihar Sharif",
+			State:       "Bihar",
+			Pincode:     "803101",
+		},
+		ConsigneeAddressSnap: &models.BiltyAddress{
+			AddressLine: "Ashok Rajpath",
+			City:        "Patna",
+			State:       "Bihar",
+			Pincode:     "800004",
+		},
+		Goods: []models.Goods{
+			{Particulars: "Cement", NumOfPkts: 40, WeightKG: num(2000), Rate: rs(1.1), Per: str("Kg"), Amount: rs(2200)},
+			{Particulars: "Tiles", NumOfPkts: 5, WeightKG: num(250), Rate: rs(50), Per: str("Pkt"), Amount: rs(250)},
+		},
+	}
+}
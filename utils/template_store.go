@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/hariomtransport/backend/models"
+	"github.com/hariomtransport/backend/repository"
+	"github.com/hariomtransport/backend/templates"
+)
+
+// DefaultTemplateName is the template used for standard bilty prints
+const DefaultTemplateName = "bilty_template"
+
+// BuiltinVersion labels templates loaded from disk or the embedded bundle
+const BuiltinVersion = "builtin"
+
+// TemplateStore resolves the active version of a print template and keeps
+// parsed templates cached so each one is parsed only once.
+type TemplateStore struct {
+	Repo repository.TemplateRepository
+	Dir  string // optional directory overriding the embedded templates
+
+	mu     sync.RWMutex
+	parsed map[string]*template.Template // keyed by name@version
+	active map[string]string             // name -> active version label
+}
+
+func NewTemplateStore(repo repository.TemplateRepository, dir string) *TemplateStore {
+	return &TemplateStore{
+		Repo:   repo,
+		Dir:    dir,
+		parsed: make(map[string]*template.Template),
+		active: make(map[string]string),
+	}
+}
+
+// Active returns the parsed active template for name along with its version label.
+// Names without an uploaded active version fall back to the bundled template.
+func (s *TemplateStore) Active(name string) (*template.Template, string, error) {
+	s.mu.RLock()
+	version, ok := s.active[name]
+	var tmpl *template.Template
+	if ok {
+		tmpl = s.parsed[name+"@"+version]
+	}
+	s.mu.RUnlock()
+	if tmpl != nil {
+		return tmpl, version, nil
+	}
+
+	version = BuiltinVersion
+	var src string
+	if s.Repo != nil {
+		t, err := s.Repo.GetActiveTemplate(name)
+		if err != nil {
+			return nil, "", err
+		}
+		if t != nil {
+			version = strconv.Itoa(t.Version)
+			src = t.Content
+		}
+	}
+
+	if version == BuiltinVersion {
+		var err error
+		src, err = s.builtinSource(name)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	tmpl, err := s.parse(name, version, src)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	s.active[name] = version
+	s.mu.Unlock()
+	return tmpl, version, nil
+}
+
+// Get returns the parsed template for a stored version
+func (s *TemplateStore) Get(t *models.BiltyTemplate) (*template.Template, error) {
+	return s.parse(t.Name, strconv.Itoa(t.Version), t.Content)
+}
+
+// Validate parses content without caching it, for checking uploads
+func (s *TemplateStore) Validate(name, content string) error {
+	_, err := template.New(name).Parse(content)
+	return err
+}
+
+// Activate switches the active version and drops the cached choice
+func (s *TemplateStore) Activate(id int64) (*models.BiltyTemplate, error) {
+	t, err := s.Repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("template %d not found", id)
+	}
+	if err := s.Repo.ActivateTemplate(id); err != nil {
+		return nil, err
+	}
+	s.Invalidate(t.Name)
+	return t, nil
+}
+
+// Rollback re-activates the version preceding the currently active one
+func (s *TemplateStore) Rollback(name string) (*models.BiltyTemplate, error) {
+	versions, err := s.Repo.GetTemplates(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// versions are newest first; pick the first one older than the active version
+	activeSeen := false
+	for _, t := range versions {
+		if t.IsActive {
+			activeSeen = true
+			continue
+		}
+		if activeSeen {
+			return s.Activate(t.ID)
+		}
+	}
+	if !activeSeen {
+		return nil, fmt.Errorf("template %q has no active version", name)
+	}
+	return nil, fmt.Errorf("template %q has no earlier version to roll back to", name)
+}
+
+// Invalidate forgets the cached active version for name
+func (s *TemplateStore) Invalidate(name string) {
+	s.mu.Lock()
+	delete(s.active, name)
+	s.mu.Unlock()
+}
+
+func (s *TemplateStore) parse(name, version, src string) (*template.Template, error) {
+	key := name + "@" + version
+
+	s.mu.RLock()
+	tmpl, ok := s.parsed[key]
+	s.mu.RUnlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New(name).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", key, err)
+	}
+
+	s.mu.Lock()
+	s.parsed[key] = tmpl
+	s.mu.Unlock()
+	return tmpl, nil
+}
+
+// builtinSource reads name.html from the configured directory or the embedded bundle
+func (s *TemplateStore) builtinSource(name string) (string, error) {
+	file := name + ".html"
+	if s.Dir != "" {
+		b, err := os.ReadFile(filepath.Join(s.Dir, file))
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %w", file, err)
+		}
+	}
+
+	b, err := templates.FS.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("template %s not found: %w", file, err)
+	}
+	return string(b), nil
+}
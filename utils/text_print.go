@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hariomtransport/backend/models"
+)
+
+// Print formats served besides PDF
+const (
+	FormatText   = "text"   // fixed-column text for dot-matrix printers
+	FormatESCPOS = "escpos" // ESC/POS byte stream for thermal printers
+)
+
+// DefaultTextLayout fits an 80 column, 36 line pre-printed bilty form. Branches
+// with a different book set InitialSetup.TextLayout.
+var DefaultTextLayout = models.TextLayout{
+	Width:  80,
+	Height: 36,
+	Fields: []models.TextField{
+		{Field: "company_name", Row: 0, Col: 0, Width: 80, Align: "center"},
+		{Field: "copy_title", Row: 1, Col: 0, Width: 40},
+		{Field: "watermark", Row: 1, Col: 40, Width: 40, Align: "right"},
+		{Field: "bilty_no", Row: 3, Col: 10, Width: 20},
+		{Field: "date", Row: 3, Col: 60, Width: 20},
+		{Field: "from", Row: 4, Col: 10, Width: 30},
+		{Field: "to", Row: 4, Col: 50, Width: 30},
+		{Field: "consignor", Row: 6, Col: 14, Width: 66},
+		{Field: "consignor_address", Row: 7, Col: 14, Width: 66},
+		{Field: "consignee", Row: 8, Col: 14, Width: 66},
+		{Field: "consignee_address", Row: 9, Col: 14, Width: 66},
+		{Field: "gstin", Row: 10, Col: 14, Width: 20},
+		{Field: "inv_no", Row: 10, Col: 50, Width: 30},
+		{Field: "pvt_marks", Row: 11, Col: 14, Width: 30},
+		{Field: "value", Row: 11, Col: 60, Width: 20, Align: "right"},
+		{Field: "hamali", Row: 26, Col: 60, Width: 20, Align: "right"},
+		{Field: "dd_charges", Row: 27, Col: 60, Width: 20, Align: "right"},
+		{Field: "other_charges", Row: 28, Col: 60, Width: 20, Align: "right"},
+		{Field: "total", Row: 29, Col: 60, Width: 20, Align: "right"},
+		{Field: "total_words", Row: 30, Col: 0, Width: 80},
+		{Field: "remarks", Row: 32, Col: 0, Width: 80},
+	},
+	GoodsRow:   14,
+	GoodsLines: 10,
+	GoodsColumns: []models.TextField{
+		{Field: "particulars", Col: 0, Width: 30},
+		{Field: "pkts", Col: 31, Width: 6, Align: "right"},
+		{Field: "weight", Col: 38, Width: 9, Align: "right"},
+		{Field: "rate", Col: 48, Width: 9, Align: "right"},
+		{Field: "per", Col: 58, Width: 6},
+		{Field: "amount", Col: 65, Width: 15, Align: "right"},
+	},
+}
+
+// textLayout returns the layout configured in the initial setup, or DefaultTextLayout
+func textLayout(initial *models.InitialSetup) models.TextLayout {
+	if initial != nil && initial.TextLayout != nil {
+		return *initial.TextLayout
+	}
+	return DefaultTextLayout
+}
+
+// RenderBiltyText lays each copy out on the fixed-column grid. Lines end in
+// CRLF and copies are separated by a form feed so every copy starts on a new form.
+func RenderBiltyText(in RenderInput) []byte {
+	layout := textLayout(in.Initial)
+
+	var out bytes.Buffer
+	for i, c := range in.Copies {
+		if i > 0 {
+			out.WriteByte('\f')
+		}
+		data := biltyPDFData(in, c)
+		values := textFieldValues(data)
+
+		page := newTextPage(layout.Width, layout.Height)
+		for _, f := range layout.Fields {
+			page.put(f.Row, f.Col, f.Width, f.Align, values[f.Field])
+		}
+
+		goods := in.Bilty.Goods
+		for n, g := range goods {
+			if n >= layout.GoodsLines {
+				break
+			}
+			row := layout.GoodsRow + n
+			if n == layout.GoodsLines-1 && len(goods) > layout.GoodsLines {
+				// No room left on the form, say what was left out
+				page.put(row, 0, layout.Width, "", fmt.Sprintf("... %d more items", len(goods)-n))
+				break
+			}
+			cells := goodsFieldValues(g)
+			for _, col := range layout.GoodsColumns {
+				page.put(row, col.Col, col.Width, col.Align, cells[col.Field])
+			}
+		}
+
+		page.writeTo(&out)
+	}
+	return out.Bytes()
+}
+
+// textFieldValues maps layout field names to the values printed for them
+func textFieldValues(data models.BiltyPDFData) map[string]string {
+	b := data.Bilty
+	values := map[string]string{
+		"bilty_no":      strconv.FormatInt(b.BiltyNo, 10),
+		"date":          data.Date,
+		"from":          b.FromLocation,
+		"to":            b.ToLocation,
+		"gstin":         stringValue(b.GSTIN),
+		"inv_no":        stringValue(b.InvNo),
+		"pvt_marks":     stringValue(b.PVTMarks),
+		"permit_no":     stringValue(b.PermitNo),
+		"value":         moneyValue(b.ValueRupees),
+		"hamali":        moneyValue(b.Hamali),
+		"dd_charges":    moneyValue(b.DDCharges),
+		"other_charges": moneyValue(b.OtherCharges),
+		"fov":           moneyValue(b.FOV),
+		"total":         data.Total.String(),
+		"total_words":   data.TotalWords,
+		"remarks":       stringValue(b.Remarks),
+		"copy_title":    data.CopyTitle,
+		"watermark":     data.Watermark,
+		"contacts":      data.Contacts,
+	}
+	if data.Company != nil {
+		values["company_name"] = data.Company.CompanyName
+		values["company_gstin"] = data.Company.GSTIN
+	}
+	if b.ConsignorCompany != nil {
+		values["consignor"] = b.ConsignorCompany.Name
+		values["consignor_gstin"] = stringValue(b.ConsignorCompany.GSTIN)
+	}
+	if b.ConsigneeCompany != nil {
+		values["consignee"] = b.ConsigneeCompany.Name
+		values["consignee_gstin"] = stringValue(b.ConsigneeCompany.GSTIN)
+	}
+	values["consignor_address"] = addressLine(b.ConsignorAddressSnap)
+	values["consignee_address"] = addressLine(b.ConsigneeAddressSnap)
+	return values
+}
+
+// goodsFieldValues maps goods column names to the values printed for them
+func goodsFieldValues(g models.Goods) map[string]string {
+	return map[string]string{
+		"particulars": g.Particulars,
+		"pkts":        strconv.Itoa(g.NumOfPkts),
+		"weight":      quantityValue(g.WeightKG),
+		"rate":        moneyValue(g.Rate),
+		"per":         stringValue(g.Per),
+		"amount":      moneyValue(g.Amount),
+	}
+}
+
+func addressLine(a *models.BiltyAddress) string {
+	if a == nil {
+		return ""
+	}
+	parts := make([]string, 0, 4)
+	for _, p := range []string{a.AddressLine, a.City, a.State, a.Pincode} {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func moneyValue(m *models.Money) string {
+	if m == nil {
+		return ""
+	}
+	return m.String()
+}
+
+func quantityValue(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
+
+// printable keeps s on one line and replaces what the printer's
+// character set can't show
+func printable(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fit pads or cuts s to width with the given alignment
+func fit(s string, width int, align string) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	pad := width - len(s)
+	switch align {
+	case "right":
+		return strings.Repeat(" ", pad) + s
+	case "center":
+		return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+	}
+	return s + strings.Repeat(" ", pad)
+}
+
+// textPage is a fixed grid of characters
+type textPage struct {
+	width int
+	lines [][]byte
+}
+
+func newTextPage(width, height int) *textPage {
+	p := &textPage{width: width, lines: make([][]byte, height)}
+	for i := range p.lines {
+		p.lines[i] = bytes.Repeat([]byte{' '}, width)
+	}
+	return p
+}
+
+// put writes s into the cell at row, col; anything off the page is dropped
+func (p *textPage) put(row, col, width int, align, s string) {
+	if row < 0 || row >= len(p.lines) || col < 0 || col >= p.width || width <= 0 {
+		return
+	}
+	if col+width > p.width {
+		width = p.width - col
+	}
+	copy(p.lines[row][col:], fit(printable(s), width, align))
+}
+
+// writeTo writes the page up to its last non-blank line
+func (p *textPage) writeTo(out *bytes.Buffer) {
+	last := len(p.lines) - 1
+	for last >= 0 && len(bytes.TrimRight(p.lines[last], " ")) == 0 {
+		last--
+	}
+	for _, line := range p.lines[:last+1] {
+		out.Write(bytes.TrimRight(line, " "))
+		out.WriteString("\r\n")
+	}
+}
+
+// PrintBiltyText renders the bilty in a text format (FormatText or FormatESCPOS)
+// with the requested copies. Reprints are marked and counted as for PDFs.
+func (g *PDFGenerator) PrintBiltyText(biltyID int64, opts models.PDFOptions, format string) ([]byte, error) {
+	bilty, err := g.Repo.GetBiltyForPDF(biltyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bilty: %w", err)
+	}
+	if bilty == nil {
+		return nil, ErrBiltyNotFound
+	}
+
+	initial, err := g.Repo.GetInitialForPDF()
+	if err != nil {
+		return nil, err
+	}
+
+	copies, err := ResolveCopies(initial, opts.Copies)
+	if err != nil {
+		return nil, err
+	}
+	// Text printers only have ASCII, so these prints are always in English
+	in := RenderInput{Initial: initial, Bilty: bilty, Copies: copies, Reprint: opts.Reprint, Language: LanguageEnglish}
+	if _, err := g.printVariant(&in); err != nil {
+		return nil, err
+	}
+	var out []byte
+	switch format {
+	case FormatText:
+		out = RenderBiltyText(in)
+	case FormatESCPOS:
+		out = RenderBiltyESCPOS(in, 0)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidPDFOptions, format)
+	}
+
+	g.countReprint(biltyID, in.Reprint)
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package utils
+
+import "html/template"
+
+// Watermarks printed diagonally across a bilty copy
+const (
+	WatermarkDraft     = "DRAFT"
+	WatermarkCancelled = "CANCELLED"
+	WatermarkDuplicate = "DUPLICATE"
+)
+
+// duplicateVariantSuffix keeps duplicate prints apart from the original PDF of a copy set
+const duplicateVariantSuffix = "~duplicate"
+
+// BiltyWatermark returns the watermark for a bilty in the given status.
+// reprint marks a complete bilty that has already been issued.
+func BiltyWatermark(status string, reprint bool) string {
+	switch status {
+	case "draft":
+		return WatermarkDraft
+	case "cancelled":
+		return WatermarkCancelled
+	}
+	if reprint {
+		return WatermarkDuplicate
+	}
+	return ""
+}
+
+// watermarkOverlay is drawn over each copy by the render pipeline, so custom
+// templates are marked too
+func watermarkOverlay(text string) string {
+	if text == "" {
+		return ""
+	}
+	return `<div class='bilty-watermark'>` + template.HTMLEscapeString(text) + `</div>`
+}